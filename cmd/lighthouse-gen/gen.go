@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/registry"
+)
+
+type (
+	// file is the data of the generated file.
+	file struct {
+		Package string
+		Source  string
+		Table   string
+		Errors  []constructor
+	}
+
+	// constructor is the data of a single generated constructor.
+	constructor struct {
+		Name     string
+		ID       string
+		Code     string
+		Langs    []string
+		Messages map[string]string
+		Desc     string
+		Hint     string
+		HttpCode int
+		Level    string
+		MetaKeys []string
+		Meta     map[string]any
+	}
+)
+
+var tmpl = template.Must(template.New("gen").Funcs(template.FuncMap{
+	"literal": literal,
+	"comment": comment,
+}).Parse(`// Code generated by lighthouse-gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"maps"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+)
+
+// {{.Table}} maps catalog ids to error constructors.
+var {{.Table}} = map[string]func(meta ...map[string]any) *sperror.Error{
+{{- range .Errors}}
+	{{literal .ID}}: {{.Name}},
+{{- end}}
+}
+{{range .Errors}}
+// {{.Name}} creates the {{literal .ID}} error: {{comment (index .Messages "en")}}.
+// The source of the error is set to the caller, meta is added to the default metadata.
+func {{.Name}}(meta ...map[string]any) *sperror.Error {
+	s := sperror.Sample{
+		Code: {{literal .Code}},
+		Messages: map[string]string{
+		{{- $msgs := .Messages}}
+		{{- range .Langs}}
+			{{literal .}}: {{literal (index $msgs .)}},
+		{{- end}}
+		},
+		{{- if .Desc}}
+		Desc: {{literal .Desc}},
+		{{- end}}
+		{{- if .Hint}}
+		Hint: {{literal .Hint}},
+		{{- end}}
+		{{- if .HttpCode}}
+		HttpCode: {{.HttpCode}},
+		{{- end}}
+		Level: {{.Level}},
+		{{- if .MetaKeys}}
+		Meta: map[string]any{
+		{{- $meta := .Meta}}
+		{{- range .MetaKeys}}
+			{{literal .}}: {{literal (index $meta .)}},
+		{{- end}}
+		},
+		{{- end}}
+	}
+	{{- if not .MetaKeys}}
+	if len(meta) != 0 {
+		s.Meta = make(map[string]any)
+	}
+	{{- end}}
+	for _, m := range meta {
+		maps.Copy(s.Meta, m)
+	}
+	return sperror.HelperNew(s)
+}
+{{end -}}
+`))
+
+// generate renders the Go source of constructors for the catalog entries.
+func generate(entries []registry.Entry, pkg, table, source string) ([]byte, error) {
+	f := file{Package: pkg, Table: table, Source: source}
+	names := make(map[string]string, len(entries))
+
+	for _, e := range entries {
+		name := identifier(e.ID)
+		if prev, ok := names[name]; ok {
+			return nil, fmt.Errorf("ids %q and %q produce the same constructor name %s", prev, e.ID, name)
+		}
+		names[name] = e.ID
+
+		lvl, _ := levels.Parse(e.Level)
+		c := constructor{
+			Name:     name,
+			ID:       e.ID,
+			Code:     e.Sample().Code,
+			Messages: e.Messages,
+			Desc:     e.Desc,
+			Hint:     e.Hint,
+			HttpCode: e.HttpCode,
+			Level:    levelConst(lvl),
+			Meta:     e.Meta,
+		}
+		for lg := range e.Messages {
+			c.Langs = append(c.Langs, lg)
+		}
+		slices.Sort(c.Langs)
+		for k, v := range e.Meta {
+			if _, err := literal(v); err != nil {
+				return nil, fmt.Errorf("%q: meta %q: %w", e.ID, k, err)
+			}
+			c.MetaKeys = append(c.MetaKeys, k)
+		}
+		slices.Sort(c.MetaKeys)
+
+		f.Errors = append(f.Errors, c)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// literal returns the Go literal of a catalog value.
+// Only values that YAML and JSON decode to plain Go values are supported: nil, booleans, numbers, strings,
+// lists and maps of them. Other values, e.g. YAML timestamps, have to be quoted in the catalog.
+func literal(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "nil", nil
+	case bool, string:
+		return fmt.Sprintf("%#v", v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%T(%d)", v, v), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", fmt.Errorf("%v has no Go literal", v)
+		}
+		f := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(f, ".e") {
+			f += ".0" // keep it a float64 in map[string]any
+		}
+		return f, nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			lit, err := literal(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = lit
+		}
+		return "[]any{" + strings.Join(items, ", ") + "}", nil
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(v))
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			lit, err := literal(v[k])
+			if err != nil {
+				return "", err
+			}
+			items[i] = strconv.Quote(k) + ": " + lit
+		}
+		return "map[string]any{" + strings.Join(items, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("unsupported value %v of type %T, quote it to keep it as a string", v, v)
+}
+
+// comment makes s safe to be continued in a line comment.
+func comment(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimRight("// "+lines[i], " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// levelConst returns the constant of lvl in the levels package, e.g. levels.LevelUser.
+func levelConst(lvl levels.Level) string {
+	name := lvl.Name()
+	return "levels.Level" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// identifier converts a catalog id like "billing.card_declined" into an exported name like ErrBillingCardDeclined.
+func identifier(id string) string {
+	var b strings.Builder
+	b.WriteString("Err")
+	for _, part := range strings.FieldsFunc(id, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r := []rune(part)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"github.com/s4bb4t/lighthouse/pkg/core/registry"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	entries, err := registry.ReadCatalog(os.DirFS("testdata"), "users.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(entries, "errs", "Catalog", "users.yaml")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+
+	const golden = "testdata/users_gen.go.golden"
+	if *update {
+		if err = os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generate() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerate_Collision(t *testing.T) {
+	entries := []registry.Entry{
+		{ID: "user.not_found", Messages: map[string]string{"en": "a"}, Level: "user"},
+		{ID: "user-not-found", Messages: map[string]string{"en": "b"}, Level: "user"},
+	}
+	if _, err := generate(entries, "errs", "Catalog", "test.yaml"); err == nil {
+		t.Errorf("generate() error = nil, want name collision")
+	}
+}
+
+func TestGenerate_Literals(t *testing.T) {
+	entries := []registry.Entry{{
+		ID:       "quota.exceeded",
+		Messages: map[string]string{"en": "Quota exceeded\n\nfunc init() { panic(1) }"},
+		Level:    "user",
+		Meta: map[string]any{
+			"ratio": 1.0,
+			"limit": uint64(4),
+			"tags":  []any{"a", 2, nil},
+			"scope": map[string]any{"b": true, "a": 0.5},
+		},
+	}}
+	src, err := generate(entries, "errs", "Catalog", "test.yaml")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "errs.go", src, 0); err != nil {
+		t.Fatalf("generate() produced invalid Go: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"error: Quota exceeded\n//\n// func init() { panic(1) }.\n",
+		`"ratio": 1.0,`, `"limit": uint64(4),`, `"tags":  []any{"a", 2, nil},`, `"scope": map[string]any{"a": 0.5, "b": true},`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generate() lacks %q:\n%s", want, src)
+		}
+	}
+
+	entries[0].Meta = map[string]any{"since": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if _, err = generate(entries, "errs", "Catalog", "test.yaml"); err == nil {
+		t.Errorf("generate() of a timestamp error = nil")
+	}
+}
+
+func Test_identifier(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "user_not_found", want: "ErrUserNotFound"},
+		{id: "billing.card-declined", want: "ErrBillingCardDeclined"},
+		{id: "http 404", want: "ErrHttp404"},
+		{id: "ошибка_входа", want: "ErrОшибкаВхода"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := identifier(tt.id); got != tt.want {
+				t.Errorf("identifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Command lighthouse-gen turns an error catalog into typed Go constructors.
+//
+// It reads a YAML or JSON catalog (see registry.Entry) and writes a Go file with one exported constructor per entry
+// and a lookup table of constructors keyed by catalog id. Typos in error ids then become compile errors.
+//
+// Usage:
+//
+//	//go:generate go run github.com/s4bb4t/lighthouse/cmd/lighthouse-gen -in errors.yaml -out errors_gen.go
+//
+// Flags:
+//
+//	-in     catalog file (.yaml, .yml or .json)
+//	-out    generated Go file, "-" for stdout (default: catalog name with _gen.go suffix)
+//	-pkg    package name (default: $GOPACKAGE set by go generate)
+//	-table  name of the lookup table (default: Catalog)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/registry"
+)
+
+func main() {
+	in := flag.String("in", "", "catalog file (.yaml, .yml or .json)")
+	out := flag.String("out", "", `generated Go file, "-" for stdout`)
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name")
+	table := flag.String("table", "Catalog", "name of the lookup table")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg, *table); err != nil {
+		fmt.Fprintf(os.Stderr, "lighthouse-gen: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg, table string) error {
+	if in == "" {
+		return fmt.Errorf("-in is required")
+	}
+	if pkg == "" {
+		return fmt.Errorf("-pkg is required outside of go generate")
+	}
+	if out == "" {
+		out = strings.TrimSuffix(in, filepath.Ext(in)) + "_gen.go"
+	}
+
+	entries, err := registry.ReadCatalog(os.DirFS(filepath.Dir(in)), filepath.Base(in))
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(entries, pkg, table, filepath.Base(in))
+	if err != nil {
+		return err
+	}
+
+	if out == "-" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0644)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/httperr"
 	"github.com/s4bb4t/lighthouse/pkg/logger"
 	"net/http"
 )
@@ -45,6 +46,20 @@ func ExampleRouter() error {
 	return nil
 }
 
+// ExampleHandler shows the same top-level handling done by httperr.
+//
+// Handlers just return errors: httperr logs the full chain, spins it to LevelUser and renders
+// the safe layer in the client's language and format (JSON, XML or CSV).
+func ExampleHandler() http.Handler {
+	return httperr.Handle(logger.Noop(), func(w http.ResponseWriter, r *http.Request) error {
+		if err := ExampleApi(r.URL.Query().Get("a")); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
+
 // ExampleApi represents the HTTP/business logic layer.
 //
 // It checks input, performs validations, and delegates to lower-level services.
@@ -101,7 +116,7 @@ func ExampleApi(a any) error {
 func ExampleApp(a any) (any, error) {
 	_, err := exampleApp(a)
 	if err != nil {
-		logger.Noop().Error(sperror.Ensure(err), levels.LevelError)
+		logger.Noop().ErrorWithLevel(sperror.Ensure(err), levels.LevelError)
 		return nil, err
 	}
 	return nil, nil
@@ -8,6 +8,8 @@ require (
 	github.com/jszwec/csvutil v1.10.0
 	go.etcd.io/bbolt v1.4.0
 	go.uber.org/zap v1.27.0
+	google.golang.org/protobuf v1.36.9
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
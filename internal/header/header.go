@@ -0,0 +1,48 @@
+// Package header parses HTTP header values shared by sperror and httperr.
+package header
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Weighted parses a comma-separated list with optional q-values, e.g. Accept or Accept-Language,
+// and returns its trimmed values ordered by descending quality. Empty values and values with q=0 are dropped.
+func Weighted(header string) []string {
+	type item struct {
+		val string
+		q   float64
+	}
+
+	var items []item
+	for _, part := range strings.Split(header, ",") {
+		val, params, _ := strings.Cut(part, ";")
+		val = strings.TrimSpace(val)
+		if val == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if k, v, _ := strings.Cut(strings.TrimSpace(p), "="); k == "q" {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > 0 {
+			items = append(items, item{val: val, q: q})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+
+	res := make([]string, len(items))
+	for i, it := range items {
+		res[i] = it.val
+	}
+	return res
+}
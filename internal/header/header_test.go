@@ -0,0 +1,26 @@
+package header
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWeighted(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{header: "", want: []string{}},
+		{header: "da, en-gb;q=0.8, en;q=0.7", want: []string{"da", "en-gb", "en"}},
+		{header: "en;q=0.1, fr;q=0, uk", want: []string{"uk", "en"}},
+		{header: "text/csv;charset=utf-8;q=0.5, application/xml", want: []string{"application/xml", "text/csv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Weighted(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Weighted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
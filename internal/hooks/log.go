@@ -1,16 +1,25 @@
 package hooks
 
 import (
+	"fmt"
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
 	"go.uber.org/zap/zapcore"
 	"log/slog"
+	"strings"
 )
 
 func Zap(e *sperror.Error, lvl levels.Level) []zapcore.Field {
 	err := e.Spin(lvl)
-	var f []zapcore.Field
+	f := make([]zapcore.Field, 0, 8)
 
+	if code := err.ErrCode(); code != "" {
+		f = append(f, zapcore.Field{
+			Key:    "code",
+			Type:   15,
+			String: code,
+		})
+	}
 	f = append(f, zapcore.Field{
 		Key:    "desc",
 		Type:   15,
@@ -26,14 +35,50 @@ func Zap(e *sperror.Error, lvl levels.Level) []zapcore.Field {
 		Type:   15,
 		String: err.Source(),
 	})
+	if st := err.StackTrace(); st != nil {
+		f = append(f, zapcore.Field{
+			Key:    "stack",
+			Type:   15,
+			String: st.String(),
+		})
+	}
+	if missing := err.MissingParams(); len(missing) != 0 {
+		f = append(f, zapcore.Field{
+			Key:    "missing_params",
+			Type:   15,
+			String: strings.Join(missing, ","),
+		})
+	}
+	for _, key := range sperror.ContextKeys() {
+		if v, ok := e.ContextValue(key); ok {
+			f = append(f, zapcore.Field{
+				Key:    key,
+				Type:   15,
+				String: fmt.Sprint(v),
+			})
+		}
+	}
+	if lvl == levels.LevelDebug {
+		f = append(f, zapcore.Field{
+			Key:    "chain",
+			Type:   15,
+			String: fmt.Sprintf("%+v", e),
+		})
+	}
 
 	return f
 }
 
 func Slog(e *sperror.Error, lvl levels.Level) []any {
 	err := e.Spin(lvl)
-	var f []any
+	f := make([]any, 0, 8)
 
+	if code := err.ErrCode(); code != "" {
+		f = append(f, slog.Attr{
+			Key:   "code",
+			Value: slog.StringValue(code),
+		})
+	}
 	f = append(f, slog.Attr{
 		Key:   "desc",
 		Value: slog.StringValue(err.Desc()),
@@ -52,6 +97,32 @@ func Slog(e *sperror.Error, lvl levels.Level) []any {
 		Key:   "source",
 		Value: slog.StringValue(err.Source()),
 	})
+	if st := err.StackTrace(); st != nil {
+		f = append(f, slog.Attr{
+			Key:   "stack",
+			Value: slog.StringValue(st.String()),
+		})
+	}
+	if missing := err.MissingParams(); len(missing) != 0 {
+		f = append(f, slog.Attr{
+			Key:   "missing_params",
+			Value: slog.AnyValue(missing),
+		})
+	}
+	for _, key := range sperror.ContextKeys() {
+		if v, ok := e.ContextValue(key); ok {
+			f = append(f, slog.Attr{
+				Key:   key,
+				Value: slog.AnyValue(v),
+			})
+		}
+	}
+	if lvl == levels.LevelDebug {
+		f = append(f, slog.Attr{
+			Key:   "chain",
+			Value: slog.StringValue(fmt.Sprintf("%+v", e)),
+		})
+	}
 
 	return f
 }
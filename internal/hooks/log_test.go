@@ -1,10 +1,12 @@
 package hooks
 
 import (
+	"context"
 	"fmt"
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	sp2 "github.com/s4bb4t/lighthouse/pkg/core/sperror"
 	"go.uber.org/zap"
+	"log/slog"
 	"testing"
 )
 
@@ -15,6 +17,21 @@ func TestZap(t *testing.T) {
 	}
 }
 
+func TestSlog_ContextMeta(t *testing.T) {
+	ctx := sp2.WithRequestID(context.Background(), "req-1")
+	err := sp2.WrapNew(DB().WithContext(ctx), sp2.Sample{Desc: "outer", Level: levels.LevelInfo})
+
+	var found bool
+	for _, a := range Slog(err, levels.LevelInfo) {
+		if attr, ok := a.(slog.Attr); ok && attr.Key == sp2.MetaRequestID {
+			found = attr.Value.String() == "req-1"
+		}
+	}
+	if !found {
+		t.Errorf("Slog() has no %s attribute of the inner layer", sp2.MetaRequestID)
+	}
+}
+
 func Api() *sp2.Error {
 	err := App()
 	return sp2.WrapNew(err, sp2.Sample{
@@ -48,3 +65,23 @@ func DB() *sp2.Error {
 		Level: levels.LevelDebug,
 	})
 }
+
+func BenchmarkSlog(b *testing.B) {
+	err := Api()
+
+	b.ReportAllocs()
+	for range b.N {
+		_ = Slog(err, levels.LevelError)
+	}
+}
+
+func TestSlog_Allocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("allocation budgets are checked in full runs")
+	}
+	err := Api()
+	// the spun layer, the attributes slice and one boxed slog.Attr per attribute
+	if got := testing.AllocsPerRun(100, func() { _ = Slog(err, levels.LevelError) }); got > 6 {
+		t.Errorf("Slog() allocates %v times, budget is 6", got)
+	}
+}
@@ -1,10 +1,14 @@
 package lighthouse
 
 import (
+	"net/http"
+
 	"github.com/s4bb4t/lighthouse/pkg/core"
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/registry"
 	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
 	"github.com/s4bb4t/lighthouse/pkg/logger"
+	"github.com/s4bb4t/lighthouse/pkg/recovery"
 	"github.com/s4bb4t/lighthouse/pkg/telegram"
 )
 
@@ -12,21 +16,26 @@ type Lighthouse struct {
 	log      core.Logger
 	notify   core.Notify
 	registry core.Registry
+	rec      *recovery.Recoverer
 }
 
 func New(stage, apikey string) *Lighthouse {
-	b, _ := telegram.New(apikey, nil)
-	return &Lighthouse{
-		log:    logger.New(stage, sperror.En, nil),
-		notify: b,
+	var notify core.Notify
+	if b, err := telegram.New(apikey, nil); err == nil {
+		notify = b
 	}
+	return ManualNew(logger.New(stage, sperror.En, nil), notify)
 }
 
 // ManualNew manually creates Lighthouse
+//
+// Recovered panics are alerted to telegram.DefaultDevGroup
 func ManualNew(log core.Logger, notify core.Notify) *Lighthouse {
 	return &Lighthouse{
-		log:    log,
-		notify: notify,
+		log:      log,
+		notify:   notify,
+		registry: registry.New(),
+		rec:      recovery.New(log, notify).SetGroup(telegram.DefaultDevGroup),
 	}
 }
 
@@ -58,14 +67,37 @@ func (l *Lighthouse) AlertError(e error, group string) error {
 	return l.notify.Error(e, group)
 }
 
-func (l *Lighthouse) Get(id int) error {
-	panic("UNIMPLEMENTED")
+// Go runs fn in a new goroutine, logging its error and reporting its panic
+func (l *Lighthouse) Go(fn func() error) {
+	l.rec.Go(fn)
+}
+
+// Recover recovers and reports a panic of the current goroutine
+//
+// Usage:
+//
+//	defer lh.Recover()
+func (l *Lighthouse) Recover() {
+	if v := recover(); v != nil {
+		l.rec.Handle(v)
+	}
+}
+
+// RecoverMiddleware recovers and reports panics of HTTP handlers
+func (l *Lighthouse) RecoverMiddleware(next http.Handler) http.Handler {
+	return l.rec.Middleware(next)
+}
+
+// Get returns a fresh copy of the registered error by its id, as the registry returns it
+//
+// It returns nil if the error is not registered
+func (l *Lighthouse) Get(id uint64) error {
 	return l.registry.Get(id)
 }
 
-func (l *Lighthouse) Reg(err error) {
-	panic("UNIMPLEMENTED")
-	l.registry.Reg(err)
+// Reg registers the error in the Lighthouse's registry and returns its stable id
+func (l *Lighthouse) Reg(err error) (uint64, error) {
+	return l.registry.Reg(err)
 }
 
 //func (l *Lighthouse) AlertDebug(msg string) error {
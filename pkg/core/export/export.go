@@ -1,9 +1,18 @@
 package export
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
-	"github.com/jszwec/csvutil"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
 )
 
@@ -13,47 +22,332 @@ const (
 	Csv  = "text/csv"
 )
 
-type Error struct {
-	Msg    string `csv:"msg,omitempty" xml:"msg,omitempty"`
-	Desc   string `csv:"desc,omitempty" xml:"desc,omitempty"`
-	Hint   string `csv:"hint,omitempty" xml:"hint,omitempty"`
-	Source string `csv:"source,omitempty" xml:"source,omitempty"`
-	Level  string `csv:"level,omitempty" xml:"level,omitempty"`
-	Cause  string `csv:"cause,omitempty" xml:"cause,omitempty"`
-}
+type (
+	// Options configure exports and streams.
+	Options struct {
+		// Redact is the policy applied to errors before they are exported, e.g. sperror.DefaultPolicy().
+		// Errors are exported as they are if it is nil.
+		Redact *sperror.Policy
+		// Langs are the languages of the message columns. If empty, every language found in the exported errors
+		// is used, English first.
+		Langs []string
+		// Level limits the exported layers of every chain to the ones Spin(Level) would pass through:
+		// from the outer layer down to the deepest one with a level not above Level.
+		// Errors whose outer layer is above Level are skipped. Zero exports all layers.
+		Level levels.Level
+		// GroupBy groups the errors of HTML reports. Errors are not grouped by default.
+		GroupBy GroupBy
+		// Title is the title of HTML reports, "Error report" by default.
+		Title string
+	}
+
+	// Error is a single row of a CSV export or an element of an XML export: one layer of a Wrap chain.
+	// Layers refer to the layer wrapping them with Parent, field errors of an error built by sperror.Group
+	// refer to the layer holding them and have Field set.
+	Error struct {
+		XMLName  xml.Name  `xml:"error"`
+		ID       int       `xml:"id,attr"`
+		Parent   int       `xml:"parent,attr,omitempty"`
+		Layer    int       `xml:"layer,attr"`
+		Field    string    `xml:"field,attr,omitempty"`
+		Code     string    `xml:"code,omitempty"`
+		HttpCode int       `xml:"http_code,omitempty"`
+		Level    string    `xml:"level"`
+		Messages []Message `xml:"messages>msg,omitempty"`
+		Desc     string    `xml:"desc,omitempty"`
+		Hint     string    `xml:"hint,omitempty"`
+		Source   string    `xml:"source,omitempty"`
+		Cause    string    `xml:"cause,omitempty"`
+		Stack    string    `xml:"stack,omitempty"`
+		Retry    string    `xml:"retry,omitempty"` // explicit retry classification, see retryText
+		Meta     []Meta    `xml:"meta>entry,omitempty"`
+
+		meta map[string]any // meta as is, for the "meta" column of Encoder
+	}
 
+	// Message is the message of a layer in one language.
+	Message struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+
+	// Meta is a meta entry of a layer. Nested maps are exported as nested entries.
+	Meta struct {
+		Key     string `xml:"key,attr"`
+		Value   string `xml:",chardata"`
+		Entries []Meta `xml:"entry,omitempty"`
+	}
+
+	// document is the root element of an XML export.
+	document struct {
+		XMLName xml.Name `xml:"errors"`
+		Errors  []Error  `xml:"error"`
+	}
+)
+
+// columns are the fixed leading CSV columns; message, text and meta columns follow them.
+var columns = []string{"id", "parent", "layer", "field", "code", "http_code", "level"}
+
+// JSON exports e with the default Options, see Options.JSON.
 func JSON(e *sperror.Error) ([]byte, error) {
-	return json.Marshal(e)
+	return Options{}.JSON(e)
+}
+
+// JSON encodes the whole chain of e, see sperror.Error.MarshalJSON.
+func (o Options) JSON(e *sperror.Error) ([]byte, error) {
+	return json.Marshal(e.Redact(o.Redact))
 }
 
+// CSV exports errors with the default Options, see Options.CSV.
 func CSV(errs ...*sperror.Error) ([]byte, error) {
-	return exp(Csv, errs...)
+	return Options{}.CSV(errs...)
 }
 
+// XML exports errors with the default Options, see Options.XML.
 func XML(errs ...*sperror.Error) ([]byte, error) {
-	return exp(Xml, errs...)
+	return Options{}.XML(errs...)
 }
 
-func exp(_type string, errs ...*sperror.Error) ([]byte, error) {
-	var arr []Error
+// CSV exports errors as a table with a row per layer, see Error.
+//
+// Messages get a "msg.<lang>" column per language, meta is flattened
+// to "meta.<key>" columns, nested maps to dotted keys like "meta.request.method".
+func (o Options) CSV(errs ...*sperror.Error) ([]byte, error) {
+	rows, langs := o.rows(errs)
+
+	metas := make([]map[string]string, len(rows))
+	keys := make(map[string]struct{})
+	for i, r := range rows {
+		metas[i] = make(map[string]string)
+		flatten(metas[i], "meta.", r.Meta)
+		for k := range metas[i] {
+			keys[k] = struct{}{}
+		}
+	}
+	metaKeys := slices.Sorted(maps.Keys(keys))
+
+	header := slices.Clone(columns)
+	for _, lg := range langs {
+		header = append(header, "msg."+lg)
+	}
+	header = append(header, "desc", "hint", "source", "cause", "stack", "retry")
+	header = append(header, metaKeys...)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for i, r := range rows {
+		rec := []string{
+			strconv.Itoa(r.ID), itoa(r.Parent), strconv.Itoa(r.Layer), r.Field, r.Code, itoa(r.HttpCode), r.Level,
+		}
+		for _, m := range r.Messages {
+			rec = append(rec, m.Text)
+		}
+		rec = append(rec, r.Desc, r.Hint, r.Source, r.Cause, r.Stack, r.Retry)
+		for _, k := range metaKeys {
+			rec = append(rec, metas[i][k])
+		}
+		if err := w.Write(rec); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// XML exports errors as an <errors> document with an <error> element per layer, see Error.
+// Messages are <msg lang="..."> elements, meta is nested <entry key="..."> elements.
+func (o Options) XML(errs ...*sperror.Error) ([]byte, error) {
+	rows, _ := o.rows(errs)
+	return xml.Marshal(document{Errors: rows})
+}
+
+// rows returns the redacted layers of errs and the languages of their messages.
+func (o Options) rows(errs []*sperror.Error) ([]Error, []string) {
+	lvl := o.Level
+	if lvl == levels.LevelNoop {
+		lvl = levels.LevelDebug
+	}
+
+	var redacted []*sperror.Error
 	for _, e := range errs {
-		err := Error{}
-		err.Source = e.Source()
-		err.Msg = e.Msg(sperror.En)
-		err.Desc = e.Desc()
-		err.Hint = e.Hint()
-		if e.Caused() != nil {
-			err.Cause = e.Caused().Error()
+		if e != nil && e.Level() <= lvl {
+			redacted = append(redacted, e.Redact(o.Redact))
 		}
-		arr = append(arr, err)
 	}
 
-	switch _type {
-	case Xml:
-		return xml.Marshal(arr)
-	case Csv:
-		return csvutil.Marshal(arr)
-	default:
-		return JSON(errs[0])
+	langs := o.Langs
+	if len(langs) == 0 {
+		langs = languages(redacted, lvl)
+	}
+
+	var rows []Error
+	for _, e := range redacted {
+		rows = appendRows(rows, e, 0, "", lvl, langs)
+	}
+	return rows, langs
+}
+
+// appendRows appends a row per layer of e, which is a field error if field is set.
+// The rows of field errors of a layer follow the row of the layer.
+func appendRows(rows []Error, e *sperror.Error, parent int, field string, lvl levels.Level, langs []string) []Error {
+	for i, layer := range e.Layers() {
+		if layer.Level() > lvl {
+			break
+		}
+
+		r := Error{
+			ID:       len(rows) + 1,
+			Parent:   parent,
+			Layer:    i,
+			Field:    field,
+			Code:     layer.ErrCode(),
+			HttpCode: layer.Code(),
+			Level:    layer.Level().String(),
+			Desc:     layer.Desc(),
+			Hint:     layer.Hint(),
+			Source:   layer.Source(),
+			meta:     layer.AllMeta(),
+		}
+		r.Meta = meta(r.meta)
+		for _, lg := range langs {
+			r.Messages = append(r.Messages, Message{Lang: lg, Text: layer.Msg(lg)})
+		}
+		// the cause of a group is the join of its field errors, which get their own rows
+		if layer.Caused() != nil && len(layer.Children()) == 0 {
+			r.Cause = layer.Caused().Error()
+		}
+		if st := layer.StackTrace(); st != nil {
+			r.Stack = st.String()
+		}
+		if c, ok := layer.RetryClass(); ok {
+			r.Retry = retryText(c)
+		}
+		rows = append(rows, r)
+
+		for _, c := range layer.Children() {
+			rows = appendRows(rows, c.Err, r.ID, c.Field, levels.LevelDebug, langs)
+		}
+		parent, field = r.ID, ""
+	}
+	return rows
+}
+
+// languages returns the languages of messages of the exported layers, English first and the rest sorted.
+func languages(errs []*sperror.Error, lvl levels.Level) []string {
+	seen := make(map[string]struct{})
+	var walk func(e *sperror.Error, lvl levels.Level)
+	walk = func(e *sperror.Error, lvl levels.Level) {
+		for _, layer := range e.Layers() {
+			if layer.Level() > lvl {
+				return
+			}
+			for lg := range layer.User.Messages {
+				seen[lg] = struct{}{}
+			}
+			for _, c := range layer.Children() {
+				walk(c.Err, levels.LevelDebug)
+			}
+		}
+	}
+	for _, e := range errs {
+		walk(e, lvl)
+	}
+
+	return enFirst(slices.Sorted(maps.Keys(seen)))
+}
+
+// enFirst moves English to the front of sorted languages.
+func enFirst(langs []string) []string {
+	if i := slices.Index(langs, sperror.En); i > 0 {
+		langs = slices.Insert(slices.Delete(langs, i, i+1), 0, sperror.En)
+	}
+	return langs
+}
+
+// meta converts meta to entries sorted by key.
+func meta(m map[string]any) []Meta {
+	var res []Meta
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		entry := Meta{Key: k}
+		if nested, ok := m[k].(map[string]any); ok {
+			entry.Entries = meta(nested)
+		} else {
+			entry.Value = fmt.Sprint(m[k])
+		}
+		res = append(res, entry)
+	}
+	return res
+}
+
+// flatten writes entries to dst with dotted keys.
+func flatten(dst map[string]string, prefix string, entries []Meta) {
+	for _, e := range entries {
+		if e.Entries != nil {
+			flatten(dst, prefix+e.Key+".", e.Entries)
+			continue
+		}
+		dst[prefix+e.Key] = e.Value
+	}
+}
+
+// itoa formats n, leaving zero values empty.
+func itoa(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// retryText formats an explicit retry classification as comma-separated flags and delay,
+// e.g. "retryable,timeout,after=1.5s", or "none" for a layer classified as not retryable.
+func retryText(c sperror.RetryClass) string {
+	var parts []string
+	if c.Retryable {
+		parts = append(parts, "retryable")
+	}
+	if c.Temporary {
+		parts = append(parts, "temporary")
+	}
+	if c.Timeout {
+		parts = append(parts, "timeout")
+	}
+	if c.RetryAfter != 0 {
+		parts = append(parts, "after="+c.RetryAfter.String())
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseRetry parses the output of retryText.
+func parseRetry(s string) (sperror.RetryClass, error) {
+	var c sperror.RetryClass
+	if s == "none" {
+		return c, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		switch part {
+		case "retryable":
+			c.Retryable = true
+		case "temporary":
+			c.Temporary = true
+		case "timeout":
+			c.Timeout = true
+		default:
+			after, ok := strings.CutPrefix(part, "after=")
+			if !ok {
+				return c, fmt.Errorf("unknown retry flag %q", part)
+			}
+			d, err := time.ParseDuration(after)
+			if err != nil {
+				return c, err
+			}
+			c.RetryAfter = d
+		}
 	}
+	return c, nil
 }
@@ -1,35 +1,247 @@
 package export
 
-// TODO: refactor tests
-//
-//func TestExport(t *testing.T) {
-//	tests := []struct {
-//		name    string
-//		e       *sp2.Error
-//		exp     func(e ...*sp2.Error) ([]byte, error)
-//		wantErr bool
-//	}{
-//		{
-//			name:    "csv",
-//			e:       sp2.Registry.Get(sp2.Internal),
-//			exp:     CSV,
-//			wantErr: false,
-//		},
-//		{
-//			name:    "xml",
-//			e:       sp2.Registry.Get(sp2.Internal),
-//			exp:     XML,
-//			wantErr: false,
-//		},
-//	}
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			got, err := tt.exp(tt.e)
-//			if (err != nil) != tt.wantErr {
-//				t.Errorf("CSV() error = %v, wantErr %v", err, tt.wantErr)
-//				return
-//			}
-//			t.Log(string(got))
-//		})
-//	}
-//}
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func ExampleOptions_CSV() {
+	err := sperror.WrapNew(sperror.New(sperror.Sample{
+		Code:     "users.db",
+		Messages: map[string]string{sperror.En: "Storage failed"},
+		Desc:     "query failed",
+		Level:    levels.LevelDebug,
+	}), sperror.Sample{
+		Code:     "users.not_found",
+		Messages: map[string]string{sperror.En: "Not found", sperror.Ru: "Не найдено"},
+		Desc:     "User not found",
+		HttpCode: http.StatusNotFound,
+		Level:    levels.LevelUser,
+	})
+
+	data, _ := Options{Langs: []string{sperror.En, sperror.Ru}}.CSV(err)
+	records, _ := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	for _, r := range records {
+		fmt.Println(r[:9])
+	}
+	// Output:
+	// [id parent layer field code http_code level msg.en msg.ru]
+	// [1  0  users.not_found 404 2 Not found Не найдено]
+	// [2 1 1  users.db  255 Storage failed ]
+}
+
+func chain() *sperror.Error {
+	return sperror.WrapNew(sperror.New(sperror.Sample{
+		Code:     "users.db",
+		Messages: map[string]string{sperror.De: "Speicherfehler", sperror.En: "Storage failed"},
+		Desc:     "query failed",
+		Level:    levels.LevelDebug,
+		Cause:    fmt.Errorf("connection reset"),
+		Meta:     map[string]any{"request": map[string]any{"method": "GET", "path": "/users/42"}},
+	}), sperror.Sample{
+		Code:     "users.not_found",
+		Messages: map[string]string{sperror.En: "Not found", sperror.Ru: "Не найдено"},
+		Desc:     "User not found",
+		HttpCode: http.StatusNotFound,
+		Level:    levels.LevelUser,
+		Meta:     map[string]any{"user_id": 42, "token": "secret"},
+	})
+}
+
+// records returns the CSV records of data as maps of column to value.
+func records(t *testing.T, data []byte) []map[string]string {
+	t.Helper()
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	var res []map[string]string
+	for _, r := range rows[1:] {
+		m := make(map[string]string, len(r))
+		for i, v := range r {
+			m[rows[0][i]] = v
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+func TestOptions_JSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		exp       func(e *sperror.Error) ([]byte, error)
+		wantToken any
+	}{
+		{name: "lossless", exp: JSON, wantToken: "secret"},
+		{name: "redacted", exp: Options{Redact: sperror.DefaultPolicy()}.JSON, wantToken: sperror.Mask},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.exp(chain())
+			if err != nil {
+				t.Fatalf("JSON() error = %v", err)
+			}
+			got := new(sperror.Error)
+			if err = json.Unmarshal(data, got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got.Meta("token") != tt.wantToken || got.Depth() != 2 {
+				t.Errorf("JSON() token = %v, depth = %d, want %v, 2", got.Meta("token"), got.Depth(), tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestOptions_CSV(t *testing.T) {
+	group := new(sperror.Group).
+		Add("email", sperror.New(sperror.Sample{Code: "email.invalid", Desc: "invalid email", Level: levels.LevelUser})).
+		Add("age", sperror.New(sperror.Sample{Code: "age.negative", Desc: "negative age", Level: levels.LevelUser})).
+		Err()
+
+	tests := []struct {
+		name   string
+		opts   Options
+		errs   []*sperror.Error
+		header []string
+		want   []map[string]string
+	}{
+		{
+			name:   "empty",
+			header: []string{"id", "parent", "layer", "field", "code", "http_code", "level", "desc", "hint", "source", "cause", "stack", "retry"},
+		},
+		{
+			name: "chain",
+			opts: Options{Redact: sperror.DefaultPolicy()},
+			errs: []*sperror.Error{chain(), nil},
+			header: []string{
+				"id", "parent", "layer", "field", "code", "http_code", "level",
+				"msg.en", "msg.de", "msg.ru", "desc", "hint", "source", "cause", "stack", "retry",
+				"meta.request.method", "meta.request.path", "meta.token", "meta.user_id",
+			},
+			want: []map[string]string{
+				{
+					"id": "1", "parent": "", "layer": "0", "code": "users.not_found", "http_code": "404", "level": "2",
+					"msg.en": "Not found", "msg.ru": "Не найдено", "msg.de": "", "desc": "User not found", "cause": "",
+					"meta.user_id": "42", "meta.token": sperror.Mask, "meta.request.method": "",
+				},
+				{
+					"id": "2", "parent": "1", "layer": "1", "code": "users.db", "http_code": "", "level": "255",
+					"msg.en": "Storage failed", "msg.ru": "", "msg.de": "Speicherfehler", "desc": "query failed",
+					"cause": "connection reset", "meta.user_id": "", "meta.request.method": "GET", "meta.request.path": "/users/42",
+				},
+			},
+		},
+		{
+			name:   "level and languages",
+			opts:   Options{Langs: []string{sperror.Ru}, Level: levels.LevelError},
+			errs:   []*sperror.Error{chain(), sperror.New(sperror.Sample{Code: "debug", Level: levels.LevelDebug})},
+			header: []string{"id", "parent", "layer", "field", "code", "http_code", "level", "msg.ru", "desc", "hint", "source", "cause", "stack", "retry", "meta.token", "meta.user_id"},
+			want: []map[string]string{
+				{"id": "1", "parent": "", "code": "users.not_found", "msg.ru": "Не найдено"},
+			},
+		},
+		{
+			name:   "group",
+			errs:   []*sperror.Error{group},
+			header: []string{"id", "parent", "layer", "field", "code", "http_code", "level", "msg.en", "desc", "hint", "source", "cause", "stack", "retry"},
+			want: []map[string]string{
+				{"id": "1", "parent": "", "field": "", "cause": ""},
+				{"id": "2", "parent": "1", "field": "email", "code": "email.invalid", "desc": "invalid email"},
+				{"id": "3", "parent": "1", "field": "age", "code": "age.negative", "desc": "negative age"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.opts.CSV(tt.errs...)
+			if err != nil {
+				t.Fatalf("CSV() error = %v", err)
+			}
+			header, err := csv.NewReader(bytes.NewReader(data)).Read()
+			if err != nil {
+				t.Fatalf("CSV() header: %v", err)
+			}
+			if !reflect.DeepEqual(header, tt.header) {
+				t.Errorf("CSV() header = %v, want %v", header, tt.header)
+			}
+
+			got := records(t, data)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CSV() rows = %d, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				for col, v := range want {
+					if got[i][col] != v {
+						t.Errorf("CSV() row %d %s = %q, want %q", i, col, got[i][col], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOptions_XML(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		errs []*sperror.Error
+		want []Error
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "chain",
+			opts: Options{Langs: []string{sperror.En}, Redact: sperror.DefaultPolicy()},
+			errs: []*sperror.Error{chain()},
+			want: []Error{
+				{
+					ID: 1, Code: "users.not_found", HttpCode: http.StatusNotFound, Level: "2", Desc: "User not found",
+					Messages: []Message{{Lang: sperror.En, Text: "Not found"}},
+					Meta:     []Meta{{Key: "token", Value: sperror.Mask}, {Key: "user_id", Value: "42"}},
+				},
+				{
+					ID: 2, Parent: 1, Layer: 1, Code: "users.db", Level: "255", Desc: "query failed", Cause: "connection reset",
+					Messages: []Message{{Lang: sperror.En, Text: "Storage failed"}},
+					Meta: []Meta{{Key: "request", Entries: []Meta{
+						{Key: "method", Value: "GET"},
+						{Key: "path", Value: "/users/42"},
+					}}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.opts.XML(tt.errs...)
+			if err != nil {
+				t.Fatalf("XML() error = %v", err)
+			}
+			var doc document
+			if err = xml.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("XML() = %s: %v", data, err)
+			}
+			if doc.XMLName.Local != "errors" {
+				t.Errorf("XML() root = %q, want errors", doc.XMLName.Local)
+			}
+			for i := range doc.Errors {
+				doc.Errors[i].XMLName = xml.Name{}
+				doc.Errors[i].Source = ""
+			}
+			if !reflect.DeepEqual(doc.Errors, tt.want) {
+				t.Errorf("XML() = %+v, want %+v", doc.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/render"
+)
+
+// GroupBy is the grouping of errors in HTML reports.
+type GroupBy int
+
+const (
+	GroupNone    GroupBy = iota // a single list in the order of errors
+	GroupByCode                 // by the HTTP code of the outer layer, ascending
+	GroupByLevel                // by the level of the outer layer, the most severe first
+)
+
+type (
+	// report is the data of an HTML report.
+	report struct {
+		Title  string
+		Total  int
+		Groups []reportGroup
+	}
+
+	// reportGroup is a group of errors with the same HTTP code or level.
+	reportGroup struct {
+		Name   string
+		Errors []reportError
+	}
+
+	// reportError is an error with every layer of its chain, outer layer first.
+	reportError struct {
+		ID     string
+		Layers []reportLayer
+	}
+
+	// reportLayer is the render.LayerCard of a layer with its level name, messages in all of its languages
+	// and field errors as nested layers.
+	reportLayer struct {
+		render.Card
+		Name     string // level name with its number, e.g. "user (2)"
+		Class    string
+		Messages []Message
+		Children []reportField
+	}
+
+	// reportField is a field error of a layer.
+	reportField struct {
+		Field  string
+		Layers []reportLayer
+	}
+)
+
+// HTML exports errors with the default Options, see Options.HTML.
+func HTML(errs ...*sperror.Error) ([]byte, error) {
+	return Options{}.HTML(errs...)
+}
+
+// HTML renders errors as a self-contained HTML incident report: inline CSS, no scripts or external assets.
+//
+// The report starts with a summary table of errors, grouped by Options.GroupBy, linking to the details of every error.
+// Details show each layer of the chain as a collapsible block with its level badge, code, messages in all languages
+// (or Options.Langs), description, hint, source, cause, field errors, request context, meta and stack trace.
+// Options.Level limits the layers as in CSV.
+func (o Options) HTML(errs ...*sperror.Error) ([]byte, error) {
+	lvl := o.Level
+	if lvl == levels.LevelNoop {
+		lvl = levels.LevelDebug
+	}
+
+	r := report{Title: cmp.Or(o.Title, "Error report")}
+	groups := make(map[int]*reportGroup)
+	var keys []int
+	for _, e := range errs {
+		if e == nil || e.Level() > lvl {
+			continue
+		}
+		e = e.Redact(o.Redact)
+		r.Total++
+
+		key, name := 0, ""
+		switch o.GroupBy {
+		case GroupByCode:
+			key, name = e.Code(), codeName(e.Code())
+		case GroupByLevel:
+			key, name = -int(e.Level()), levelName(e.Level())
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &reportGroup{Name: name}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.Errors = append(g.Errors, reportError{ID: "e" + strconv.Itoa(r.Total), Layers: o.layers(e, lvl)})
+	}
+
+	if o.GroupBy != GroupNone {
+		slices.Sort(keys)
+	}
+	for _, k := range keys {
+		r.Groups = append(r.Groups, *groups[k])
+	}
+
+	var buf bytes.Buffer
+	if err := reportTmpl.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// layers returns the layers of e down to the deepest one with a level not above lvl.
+func (o Options) layers(e *sperror.Error, lvl levels.Level) []reportLayer {
+	var res []reportLayer
+	for _, layer := range e.Layers() {
+		if layer.Level() > lvl {
+			break
+		}
+
+		l := reportLayer{
+			Card:  render.LayerCard(layer, sperror.En),
+			Name:  levelName(layer.Level()),
+			Class: cmp.Or(layer.Level().Name(), "custom"),
+		}
+
+		langs := o.Langs
+		if len(langs) == 0 {
+			langs = enFirst(slices.Sorted(maps.Keys(layer.User.Messages)))
+		}
+		for _, lg := range langs {
+			if msg := layer.Msg(lg); msg != "" {
+				l.Messages = append(l.Messages, Message{Lang: lg, Text: msg})
+			}
+		}
+
+		for _, c := range layer.Children() {
+			l.Children = append(l.Children, reportField{Field: c.Field, Layers: o.layers(c.Err, levels.LevelDebug)})
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+func levelName(lvl levels.Level) string {
+	if name := lvl.Name(); name != "" {
+		return name + " (" + lvl.String() + ")"
+	}
+	return lvl.String()
+}
+
+func codeName(code int) string {
+	if code == 0 {
+		return "No HTTP code"
+	}
+	return fmt.Sprintf("%d %s", code, http.StatusText(code))
+}
+
+var reportTmpl = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body{font:14px/1.5 -apple-system,"Segoe UI",Roboto,sans-serif;color:#1f2328;background:#f6f8fa;margin:0;padding:24px}
+main{max-width:1100px;margin:0 auto}
+h1{margin:0 0 4px}
+h2{margin:32px 0 8px;font-size:18px}
+table{border-collapse:collapse;width:100%;background:#fff;margin:8px 0}
+th,td{border:1px solid #d0d7de;padding:4px 8px;text-align:left;vertical-align:top}
+th{background:#eaeef2}
+tr.group td{background:#f6f8fa;font-weight:600}
+code,pre{font:12px/1.4 ui-monospace,SFMono-Regular,Menlo,monospace}
+pre{background:#f6f8fa;padding:8px;overflow-x:auto;margin:4px 0}
+details{background:#fff;border:1px solid #d0d7de;border-radius:6px;margin:8px 0;padding:4px 12px}
+details details{margin-left:16px}
+summary{cursor:pointer;padding:4px 0}
+dl{display:grid;grid-template-columns:max-content 1fr;gap:2px 12px;margin:8px 0}
+dt{font-weight:600;color:#57606a}
+dd{margin:0}
+.muted{color:#57606a}
+.badge{display:inline-block;border-radius:10px;padding:0 8px;font-size:12px;font-weight:600;color:#fff;background:#6e7781}
+.debug{background:#8250df}.error{background:#cf222e}.info{background:#0969da}.user{background:#bf8700}.noop{background:#6e7781}
+.field{border-left:3px solid #d0d7de;padding-left:8px;margin:8px 0}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<p class="muted">{{.Total}} error(s)</p>
+<table>
+<thead><tr><th>#</th><th>Level</th><th>HTTP</th><th>Code</th><th>Message</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Groups}}
+{{- if .Name}}
+<tr class="group"><td colspan="6">{{.Name}} · {{len .Errors}}</td></tr>
+{{- end}}
+{{- range .Errors}}
+{{- $id := .ID}}
+{{- with index .Layers 0}}
+<tr><td><a href="#{{$id}}">{{$id}}</a></td><td><span class="badge {{.Class}}">{{.Name}}</span></td><td>{{if .HttpCode}}{{.HttpCode}}{{end}}</td><td>{{with .Code}}<code>{{.}}</code>{{end}}</td><td>{{with .Messages}}{{(index . 0).Text}}{{end}}</td><td>{{.Desc}}</td></tr>
+{{- end}}
+{{- end}}
+{{- end}}
+</tbody>
+</table>
+{{- range .Groups}}
+{{- if .Name}}
+<h2>{{.Name}}</h2>
+{{- end}}
+{{- range .Errors}}
+<section id="{{.ID}}">
+{{template "layers" .Layers}}
+</section>
+{{- end}}
+{{- end}}
+</main>
+</body>
+</html>
+{{define "layers"}}
+{{- range $i, $l := .}}
+<details{{if eq $i 0}} open{{end}}>
+<summary><span class="badge {{.Class}}">{{.Name}}</span> {{if .HttpCode}}<b>{{.HttpCode}}</b> {{end}}{{with .Code}}<code>{{.}}</code> {{end}}{{.Desc}} <span class="muted">layer {{$i}}</span></summary>
+<dl>
+{{- range .Messages}}
+<dt>Message <span class="muted">{{.Lang}}</span></dt><dd>{{.Text}}</dd>
+{{- end}}
+{{- with .Desc}}
+<dt>Description</dt><dd>{{.}}</dd>
+{{- end}}
+{{- with .Hint}}
+<dt>Hint</dt><dd><i>{{.}}</i></dd>
+{{- end}}
+{{- with .Source}}
+<dt>Source</dt><dd><code>{{.}}</code></dd>
+{{- end}}
+{{- with .Cause}}
+<dt>Cause</dt><dd><code>{{.}}</code></dd>
+{{- end}}
+</dl>
+{{- with .Children}}
+<h4>Fields</h4>
+{{- range .}}
+<div class="field"><b>{{.Field}}</b>{{template "layers" .Layers}}</div>
+{{- end}}
+{{- end}}
+{{- with .Request}}
+<h4>Request</h4>
+<table>{{range .}}<tr><th>{{.Key}}</th><td><code>{{.Value}}</code></td></tr>{{end}}</table>
+{{- end}}
+{{- with .Meta}}
+<h4>Meta</h4>
+<table>{{range .}}<tr><th>{{.Key}}</th><td><code>{{.Value}}</code></td></tr>{{end}}</table>
+{{- end}}
+{{- with .Stack}}
+<h4>Stack</h4>
+<pre>
+{{- range .}}
+{{.}}
+{{- end}}
+</pre>
+{{- end}}
+</details>
+{{- end}}
+{{end}}`))
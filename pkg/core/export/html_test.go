@@ -0,0 +1,114 @@
+package export
+
+import (
+	"context"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOptions_HTML(t *testing.T) {
+	ctx := sperror.WithRequestID(context.Background(), "req-7")
+	conflict := sperror.New(sperror.Sample{
+		Code:     "users.exists",
+		Messages: map[string]string{sperror.En: "Already exists"},
+		Desc:     "<script>alert(1)</script>",
+		HttpCode: http.StatusConflict,
+		Level:    levels.LevelUser,
+	}).WithContext(ctx)
+	internal := sperror.New(sperror.Sample{Code: "users.internal", HttpCode: http.StatusInternalServerError, Level: levels.LevelError})
+	group := new(sperror.Group).
+		Add("email", sperror.New(sperror.Sample{Code: "email.invalid", Desc: "invalid email", Level: levels.LevelUser})).
+		Err()
+
+	tests := []struct {
+		name    string
+		opts    Options
+		errs    []*sperror.Error
+		want    []string // in this order
+		notWant []string
+	}{
+		{
+			name: "chain",
+			opts: Options{Redact: sperror.DefaultPolicy()},
+			errs: []*sperror.Error{chain(), nil},
+			want: []string{
+				"<title>Error report</title>", "1 error(s)",
+				`<a href="#e1">e1</a>`, `badge user">user (2)`, "users.not_found", "Not found",
+				`<section id="e1">`, "<details open>", "Message <span class=\"muted\">en</span></dt><dd>Not found",
+				"Message <span class=\"muted\">ru</span></dt><dd>Не найдено", "<th>token</th><td><code>" + sperror.Mask,
+				"<details>", `badge debug">debug (255)`, "Speicherfehler", "<dt>Cause</dt><dd><code>connection reset",
+				"<th>request.method</th><td><code>GET",
+			},
+			notWant: []string{"secret", "<script", "http://", "https://", "src="},
+		},
+		{
+			name: "escaping and request",
+			opts: Options{Title: "Incident #42"},
+			errs: []*sperror.Error{conflict},
+			want: []string{
+				"<title>Incident #42</title>", "&lt;script&gt;alert(1)&lt;/script&gt;",
+				"<h4>Request</h4>", "<th>request_id</th><td><code>req-7",
+			},
+			notWant: []string{"<script>", "<h4>Meta</h4>"},
+		},
+		{
+			name: "group by code",
+			opts: Options{GroupBy: GroupByCode},
+			errs: []*sperror.Error{internal, conflict, group},
+			want: []string{
+				"400 Bad Request · 1", "409 Conflict · 1", "500 Internal Server Error · 1",
+				"<h2>400 Bad Request</h2>", "<h4>Fields</h4>", "<b>email</b>", "email.invalid",
+				"<h2>409 Conflict</h2>", "<h2>500 Internal Server Error</h2>",
+			},
+		},
+		{
+			name: "group by level",
+			opts: Options{GroupBy: GroupByLevel},
+			errs: []*sperror.Error{conflict, internal, chain()},
+			want: []string{"error (64) · 1", "user (2) · 2", "<h2>error (64)</h2>", "<h2>user (2)</h2>"},
+		},
+		{
+			name:    "level",
+			opts:    Options{Level: levels.LevelUser},
+			errs:    []*sperror.Error{chain(), internal},
+			want:    []string{"1 error(s)", "users.not_found"},
+			notWant: []string{"users.db", "users.internal"},
+		},
+		{
+			name:    "empty",
+			want:    []string{"0 error(s)", "</tbody>"},
+			notWant: []string{"<section"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.opts.HTML(tt.errs...)
+			if err != nil {
+				t.Fatalf("HTML() error = %v", err)
+			}
+			got := string(data)
+
+			rest := got
+			for _, w := range tt.want {
+				i := strings.Index(rest, w)
+				if i < 0 {
+					t.Fatalf("HTML() lacks %q in order:\n%s", w, got)
+				}
+				rest = rest[i+len(w):]
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("HTML() contains %q", w)
+				}
+			}
+			if open, closed := regexp.MustCompile(`<details[ >]`).FindAllString(got, -1), strings.Count(got, "</details>"); len(open) != closed {
+				t.Errorf("HTML() has %d <details> and %d </details>", len(open), closed)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Package pb is the Protocol Buffers form of sperror.Error for gRPC and other protobuf-based RPCs.
+//
+// sperror.proto describes an error as the list of layers of its Wrap chain, every layer with its
+// messages, description, hint, source, codes, level, meta, cause, stack, field errors, retry
+// classification and whether its texts are plain text or templates. ToProto and FromProto convert errors to and from the generated types.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative sperror.proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+)
+
+// ToProto converts e with its whole Wrap chain, see Error.
+//
+// Meta values keep their kind: integers, unsigned integers, floating point numbers, strings, bytes,
+// slices and string-keyed maps are converted as they are, anything else is converted through its JSON form.
+// Nothing is redacted, call sperror.Error.Redact before sending errors outside.
+func ToProto(e *sperror.Error) *Error {
+	if e == nil {
+		return nil
+	}
+	res := &Error{}
+	for _, layer := range e.Layers() {
+		res.Layers = append(res.Layers, toLayer(layer))
+	}
+	return res
+}
+
+// FromProto restores an error converted by ToProto.
+// Causes that were not *sperror.Error values are restored as errors that match the original by text, see sperror.TextError.
+// Integer meta values are restored as int, unsigned ones as uint, lists as []any and structs as map[string]any.
+func FromProto(p *Error) *sperror.Error {
+	if p == nil || len(p.Layers) == 0 {
+		return nil
+	}
+
+	var res *sperror.Error
+	for i := len(p.Layers) - 1; i >= 0; i-- {
+		layer := fromLayer(p.Layers[i])
+		if res != nil {
+			layer = sperror.Wrap(res, layer)
+		}
+		res = layer
+	}
+	return res
+}
+
+func toLayer(e *sperror.Error) *Layer {
+	l := &Layer{
+		Code:     e.User.Code,
+		Messages: maps.Clone(e.User.Messages),
+		Desc:     e.Core.Desc,
+		Hint:     e.Core.Hint,
+		Source:   e.Core.Source,
+		Plain:    e.Plain(),
+		HttpCode: int32(e.User.HttpCode),
+		Level:    uint32(e.User.Level),
+	}
+
+	if meta := e.AllMeta(); len(meta) != 0 {
+		l.Meta = make(map[string]*Value, len(meta))
+		for k, v := range meta {
+			l.Meta[k] = toValue(v)
+		}
+	}
+
+	for _, f := range e.StackTrace() {
+		l.Stack = append(l.Stack, &Frame{Function: f.Function, File: f.File, Line: int32(f.Line)})
+	}
+
+	// the cause of a group is the join of its children, it is restored from them
+	children := e.Children()
+	for _, c := range children {
+		l.Children = append(l.Children, &FieldError{Field: c.Field, Error: ToProto(c.Err)})
+	}
+	if cause := e.Core.Cause; cause != nil && len(children) == 0 {
+		if sp, ok := cause.(*sperror.Error); ok {
+			l.Cause = &Cause{Kind: &Cause_Error{Error: ToProto(sp)}}
+		} else {
+			l.Cause = &Cause{Kind: &Cause_Text{Text: cause.Error()}}
+		}
+	}
+
+	if c, ok := e.RetryClass(); ok {
+		l.Retry = &Retry{
+			Retryable:    c.Retryable,
+			Temporary:    c.Temporary,
+			Timeout:      c.Timeout,
+			RetryAfterMs: c.RetryAfter.Milliseconds(),
+		}
+	}
+	return l
+}
+
+func fromLayer(l *Layer) *sperror.Error {
+	s := sperror.Sample{
+		Code:     l.Code,
+		Messages: l.Messages,
+		Desc:     l.Desc,
+		Hint:     l.Hint,
+		Plain:    l.Plain,
+		HttpCode: int(l.HttpCode),
+		Level:    levels.Level(l.Level),
+	}
+	if len(l.Meta) != 0 {
+		s.Meta = make(map[string]any, len(l.Meta))
+		for k, v := range l.Meta {
+			s.Meta[k] = fromValue(v)
+		}
+	}
+	switch c := l.GetCause().GetKind().(type) {
+	case *Cause_Error:
+		s.Cause = FromProto(c.Error)
+	case *Cause_Text:
+		s.Cause = sperror.TextError(c.Text)
+	}
+
+	var st sperror.StackTrace
+	for _, f := range l.Stack {
+		st = append(st, sperror.Frame{Function: f.Function, File: f.File, Line: int(f.Line)})
+	}
+
+	// the cause of a group is the join of its children, Restore rebuilds it from them
+	var children []sperror.FieldError
+	for _, c := range l.Children {
+		children = append(children, sperror.FieldError{Field: c.Field, Err: FromProto(c.Error)})
+	}
+
+	e := sperror.Restore(s, l.Source, st, children...)
+	if r := l.Retry; r != nil {
+		e = e.SetRetryClass(sperror.RetryClass{
+			Retryable:  r.Retryable,
+			Temporary:  r.Temporary,
+			Timeout:    r.Timeout,
+			RetryAfter: time.Duration(r.RetryAfterMs) * time.Millisecond,
+		})
+	}
+	return e
+}
+
+// toValue converts a meta value, falling back to its JSON form for kinds protobuf has no values for.
+func toValue(v any) *Value {
+	if v == nil {
+		return &Value{Kind: &Value_NullValue{}}
+	}
+	switch v := v.(type) {
+	case []byte:
+		return &Value{Kind: &Value_BytesValue{BytesValue: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &Value{Kind: &Value_IntValue{IntValue: i}}
+		}
+		f, _ := v.Float64()
+		return &Value{Kind: &Value_DoubleValue{DoubleValue: f}}
+	case json.Marshaler:
+		return fromJSON(v)
+	case error:
+		return &Value{Kind: &Value_StringValue{StringValue: v.Error()}}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return &Value{Kind: &Value_BoolValue{BoolValue: rv.Bool()}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Value{Kind: &Value_IntValue{IntValue: rv.Int()}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Value{Kind: &Value_UintValue{UintValue: rv.Uint()}}
+	case reflect.Float32, reflect.Float64:
+		return &Value{Kind: &Value_DoubleValue{DoubleValue: rv.Float()}}
+	case reflect.String:
+		return &Value{Kind: &Value_StringValue{StringValue: rv.String()}}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return &Value{Kind: &Value_NullValue{}}
+		}
+		list := &ListValue{Values: make([]*Value, rv.Len())}
+		for i := range rv.Len() {
+			list.Values[i] = toValue(rv.Index(i).Interface())
+		}
+		return &Value{Kind: &Value_ListValue{ListValue: list}}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fromJSON(v)
+		}
+		if rv.IsNil() {
+			return &Value{Kind: &Value_NullValue{}}
+		}
+		st := &Struct{Fields: make(map[string]*Value, rv.Len())}
+		for it := rv.MapRange(); it.Next(); {
+			st.Fields[it.Key().String()] = toValue(it.Value().Interface())
+		}
+		return &Value{Kind: &Value_StructValue{StructValue: st}}
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return &Value{Kind: &Value_NullValue{}}
+		}
+	}
+	return fromJSON(v)
+}
+
+// fromJSON converts v through its JSON form; values that cannot be encoded are converted to their text.
+func fromJSON(v any) *Value {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return &Value{Kind: &Value_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+	d := json.NewDecoder(strings.NewReader(string(data)))
+	d.UseNumber()
+	var decoded any
+	if err = d.Decode(&decoded); err != nil {
+		return &Value{Kind: &Value_StringValue{StringValue: string(data)}}
+	}
+	return toValue(decoded)
+}
+
+func fromValue(v *Value) any {
+	switch k := v.GetKind().(type) {
+	case *Value_BoolValue:
+		return k.BoolValue
+	case *Value_IntValue:
+		return int(k.IntValue)
+	case *Value_UintValue:
+		return uint(k.UintValue)
+	case *Value_DoubleValue:
+		return k.DoubleValue
+	case *Value_StringValue:
+		return k.StringValue
+	case *Value_BytesValue:
+		return k.BytesValue
+	case *Value_ListValue:
+		list := make([]any, len(k.ListValue.GetValues()))
+		for i, item := range k.ListValue.GetValues() {
+			list[i] = fromValue(item)
+		}
+		return list
+	case *Value_StructValue:
+		st := make(map[string]any, len(k.StructValue.GetFields()))
+		for key, item := range k.StructValue.GetFields() {
+			st[key] = fromValue(item)
+		}
+		return st
+	}
+	return nil
+}
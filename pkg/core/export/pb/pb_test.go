@@ -0,0 +1,224 @@
+package pb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ExampleToProto() {
+	err := sperror.WrapNew(sperror.NotFound("user 42 not found", "Check the id"), sperror.Sample{
+		Code:     "users.get_failed",
+		Messages: map[string]string{sperror.En: "Failed to get the user"},
+	})
+
+	data, _ := proto.Marshal(ToProto(err))
+
+	var p Error
+	_ = proto.Unmarshal(data, &p)
+	restored := FromProto(&p)
+	fmt.Println(restored.ErrCode(), restored.Depth(), restored.Root().Desc())
+	// Output: users.get_failed 2 user 42 not found
+}
+
+func TestToProto(t *testing.T) {
+	var g sperror.Group
+	g.Add("email", sperror.BadRequest("invalid email", "Fix the email"))
+	g.Add("age", sperror.New(sperror.Sample{Desc: "too young", Level: levels.LevelUser, HttpCode: http.StatusUnprocessableEntity}))
+	group := g.Err().AddMeta("form", "signup")
+
+	root := sperror.New(sperror.Sample{
+		Code:     "db.timeout",
+		Messages: map[string]string{sperror.En: "Database is slow", sperror.Ru: "База медленная"},
+		Desc:     "query timed out",
+		Hint:     "Retry later",
+		HttpCode: http.StatusServiceUnavailable,
+		Level:    levels.LevelError,
+		Cause:    errors.New("context deadline exceeded"),
+		Meta: map[string]any{
+			"attempt": 3,
+			"shard":   uint8(7),
+			"ratio":   0.5,
+			"ok":      false,
+			"query":   "SELECT 1",
+			"raw":     []byte{1, 2},
+			"tags":    []string{"a", "b"},
+			"nested":  map[string]any{"n": 1, "missing": nil},
+			"timeout": 2 * time.Second,
+			"at":      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Timeout:    true,
+		RetryAfter: 1500 * time.Millisecond,
+	}).SetStack()
+
+	tests := []struct {
+		name string
+		err  *sperror.Error
+	}{
+		{
+			name: "single layer",
+			err:  root,
+		},
+		{
+			name: "wrap chain",
+			err:  sperror.Wrap(sperror.Wrap(root, sperror.New(sperror.Sample{Desc: "repository failed"})), sperror.NewSpErr().SetErrCode("svc.failed")),
+		},
+		{
+			name: "error cause",
+			err:  sperror.New(sperror.Sample{Desc: "handler failed", Cause: root}),
+		},
+		{
+			name: "plain texts",
+			err:  sperror.Ensure(errors.New(`bad body {"id":{user}}`)).AddMeta("user", 1),
+		},
+		{
+			name: "group",
+			err:  sperror.Wrap(group, sperror.New(sperror.Sample{Desc: "signup failed", Retryable: true})),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := proto.Marshal(ToProto(tt.err))
+			if err != nil {
+				t.Fatalf("proto.Marshal() error = %v", err)
+			}
+			var p Error
+			if err = proto.Unmarshal(data, &p); err != nil {
+				t.Fatalf("proto.Unmarshal() error = %v", err)
+			}
+			got := FromProto(&p)
+
+			if got.Depth() != tt.err.Depth() {
+				t.Fatalf("FromProto() depth = %d, want %d", got.Depth(), tt.err.Depth())
+			}
+			if got.Error() != tt.err.Error() {
+				t.Errorf("FromProto() = %q, want %q", got.Error(), tt.err.Error())
+			}
+			if !proto.Equal(ToProto(got), &p) {
+				t.Errorf("ToProto(FromProto()) differs from the original message")
+			}
+			for i, layer := range tt.err.Layers() {
+				equalLayer(t, fmt.Sprintf("layer %d", i), layerAt(got, i), layer)
+			}
+		})
+	}
+}
+
+func TestFromProto_Meta(t *testing.T) {
+	got := FromProto(ToProto(sperror.New(sperror.Sample{Meta: map[string]any{
+		"int":     int64(-3),
+		"uint":    uint16(3),
+		"float":   float32(1.5),
+		"bytes":   []byte("x"),
+		"list":    []int{1, 2},
+		"map":     map[string]string{"k": "v"},
+		"time":    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"err":     errors.New("boom"),
+		"nil":     nil,
+		"struct":  struct{ A int }{A: 1},
+		"int map": map[int]bool{1: true},
+	}})))
+
+	want := map[string]any{
+		"int":     -3,
+		"uint":    uint(3),
+		"float":   1.5,
+		"bytes":   []byte("x"),
+		"list":    []any{1, 2},
+		"map":     map[string]any{"k": "v"},
+		"time":    "2024-01-02T03:04:05Z",
+		"err":     "boom",
+		"nil":     nil,
+		"struct":  map[string]any{"A": 1},
+		"int map": map[string]any{"1": true},
+	}
+	if meta := got.AllMeta(); !reflect.DeepEqual(meta, want) {
+		t.Errorf("FromProto() meta = %#v, want %#v", meta, want)
+	}
+}
+
+func TestFromProto_Restore(t *testing.T) {
+	sperror.EnableStack(true)
+	defer sperror.EnableStack(false)
+
+	cause := errors.New("connection reset")
+	var g sperror.Group
+	g.Add("email", sperror.NewSpErr().SetDesc("invalid email"))
+	got := FromProto(&Error{Layers: []*Layer{
+		{Desc: "query failed", Source: "db.go:7", Cause: &Cause{Kind: &Cause_Text{Text: cause.Error()}}},
+		toLayer(g.Err().SetStackTrace(nil)),
+	}})
+
+	for i, layer := range got.Layers() {
+		if st := layer.StackTrace(); st != nil {
+			t.Errorf("layer %d StackTrace() = %v, want nil", i, st)
+		}
+	}
+	if got.Source() != "db.go:7" {
+		t.Errorf("Source() = %q, want %q", got.Source(), "db.go:7")
+	}
+	if !errors.Is(got, cause) {
+		t.Errorf("errors.Is() of the text cause = false")
+	}
+}
+
+func TestFromProto_Nil(t *testing.T) {
+	if got := FromProto(nil); got != nil {
+		t.Errorf("FromProto(nil) = %v, want nil", got)
+	}
+	if got := FromProto(&Error{}); got != nil {
+		t.Errorf("FromProto() of an empty error = %v, want nil", got)
+	}
+	if got := ToProto(nil); got != nil {
+		t.Errorf("ToProto(nil) = %v, want nil", got)
+	}
+}
+
+func layerAt(e *sperror.Error, depth int) *sperror.Error {
+	for i, layer := range e.Layers() {
+		if i == depth {
+			return layer
+		}
+	}
+	return nil
+}
+
+func equalLayer(t *testing.T, name string, got, want *sperror.Error) {
+	t.Helper()
+	if !reflect.DeepEqual(got.User, want.User) {
+		t.Errorf("%s: User = %+v, want %+v", name, got.User, want.User)
+	}
+	if got.Core.Desc != want.Core.Desc || got.Core.Hint != want.Core.Hint || got.Core.Source != want.Core.Source || got.Plain() != want.Plain() {
+		t.Errorf("%s: Core = %+v, want %+v", name, got.Core, want.Core)
+	}
+	if (got.Core.Cause == nil) != (want.Core.Cause == nil) || got.Core.Cause != nil && got.Core.Cause.Error() != want.Core.Cause.Error() {
+		t.Errorf("%s: cause = %v, want %v", name, got.Core.Cause, want.Core.Cause)
+	}
+	if !reflect.DeepEqual(got.StackTrace(), want.StackTrace()) {
+		t.Errorf("%s: stack = %v, want %v", name, got.StackTrace(), want.StackTrace())
+	}
+	gotRetry, gotSet := got.RetryClass()
+	wantRetry, wantSet := want.RetryClass()
+	if gotRetry != wantRetry || gotSet != wantSet {
+		t.Errorf("%s: retry = %+v %v, want %+v %v", name, gotRetry, gotSet, wantRetry, wantSet)
+	}
+	if got.Retryable() != want.Retryable() || got.RetryAfter() != want.RetryAfter() {
+		t.Errorf("%s: Retryable() = %v %v, want %v %v", name, got.Retryable(), got.RetryAfter(), want.Retryable(), want.RetryAfter())
+	}
+	if len(got.Children()) != len(want.Children()) {
+		t.Fatalf("%s: %d children, want %d", name, len(got.Children()), len(want.Children()))
+	}
+	for i, c := range want.Children() {
+		if got.Children()[i].Field != c.Field {
+			t.Errorf("%s: child %d field = %q, want %q", name, i, got.Children()[i].Field, c.Field)
+		}
+		equalLayer(t, name+"."+c.Field, got.Children()[i].Err, c.Err)
+	}
+}
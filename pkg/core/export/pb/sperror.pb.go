@@ -0,0 +1,979 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: sperror.proto
+
+// Structured errors of github.com/s4bb4t/lighthouse/pkg/core/sperror.
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// NullValue is the nil meta value.
+type NullValue int32
+
+const (
+	NullValue_NULL_VALUE NullValue = 0
+)
+
+// Enum value maps for NullValue.
+var (
+	NullValue_name = map[int32]string{
+		0: "NULL_VALUE",
+	}
+	NullValue_value = map[string]int32{
+		"NULL_VALUE": 0,
+	}
+)
+
+func (x NullValue) Enum() *NullValue {
+	p := new(NullValue)
+	*p = x
+	return p
+}
+
+func (x NullValue) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NullValue) Descriptor() protoreflect.EnumDescriptor {
+	return file_sperror_proto_enumTypes[0].Descriptor()
+}
+
+func (NullValue) Type() protoreflect.EnumType {
+	return &file_sperror_proto_enumTypes[0]
+}
+
+func (x NullValue) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NullValue.Descriptor instead.
+func (NullValue) EnumDescriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{0}
+}
+
+// Error is a sperror.Error with its whole Wrap chain.
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Layers of the chain, the outer one first; every layer wraps the next one.
+	Layers        []*Layer `protobuf:"bytes,1,rep,name=layers,proto3" json:"layers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_sperror_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Error) GetLayers() []*Layer {
+	if x != nil {
+		return x.Layers
+	}
+	return nil
+}
+
+// Layer is a single layer of a Wrap chain.
+type Layer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Localized messages by language, e.g. "en".
+	Messages map[string]string `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Desc     string            `protobuf:"bytes,3,opt,name=desc,proto3" json:"desc,omitempty"`
+	Hint     string            `protobuf:"bytes,4,opt,name=hint,proto3" json:"hint,omitempty"`
+	// Call site of the layer, "file:line".
+	Source   string            `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	HttpCode int32             `protobuf:"varint,6,opt,name=http_code,json=httpCode,proto3" json:"http_code,omitempty"`
+	Level    uint32            `protobuf:"varint,7,opt,name=level,proto3" json:"level,omitempty"`
+	Meta     map[string]*Value `protobuf:"bytes,8,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Unset for layers without a cause and for groups, whose cause is the join of their children.
+	Cause *Cause `protobuf:"bytes,9,opt,name=cause,proto3" json:"cause,omitempty"`
+	// Stack frames, the innermost call first.
+	Stack []*Frame `protobuf:"bytes,10,rep,name=stack,proto3" json:"stack,omitempty"`
+	// Field errors of an error built by sperror.Group.
+	Children []*FieldError `protobuf:"bytes,11,rep,name=children,proto3" json:"children,omitempty"`
+	// Explicit retry classification of the layer, unset if it is inferred.
+	Retry *Retry `protobuf:"bytes,12,opt,name=retry,proto3" json:"retry,omitempty"`
+	// Messages, desc and hint are plain text rather than templates, see sperror.Sample.Plain.
+	Plain         bool `protobuf:"varint,13,opt,name=plain,proto3" json:"plain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Layer) Reset() {
+	*x = Layer{}
+	mi := &file_sperror_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Layer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Layer) ProtoMessage() {}
+
+func (x *Layer) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Layer.ProtoReflect.Descriptor instead.
+func (*Layer) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Layer) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Layer) GetMessages() map[string]string {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *Layer) GetDesc() string {
+	if x != nil {
+		return x.Desc
+	}
+	return ""
+}
+
+func (x *Layer) GetHint() string {
+	if x != nil {
+		return x.Hint
+	}
+	return ""
+}
+
+func (x *Layer) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Layer) GetHttpCode() int32 {
+	if x != nil {
+		return x.HttpCode
+	}
+	return 0
+}
+
+func (x *Layer) GetLevel() uint32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *Layer) GetMeta() map[string]*Value {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *Layer) GetCause() *Cause {
+	if x != nil {
+		return x.Cause
+	}
+	return nil
+}
+
+func (x *Layer) GetStack() []*Frame {
+	if x != nil {
+		return x.Stack
+	}
+	return nil
+}
+
+func (x *Layer) GetChildren() []*FieldError {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *Layer) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *Layer) GetPlain() bool {
+	if x != nil {
+		return x.Plain
+	}
+	return false
+}
+
+// Cause is the cause of a layer.
+type Cause struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Cause_Text
+	//	*Cause_Error
+	Kind          isCause_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cause) Reset() {
+	*x = Cause{}
+	mi := &file_sperror_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cause) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cause) ProtoMessage() {}
+
+func (x *Cause) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cause.ProtoReflect.Descriptor instead.
+func (*Cause) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Cause) GetKind() isCause_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Cause) GetText() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Cause_Text); ok {
+			return x.Text
+		}
+	}
+	return ""
+}
+
+func (x *Cause) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Kind.(*Cause_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isCause_Kind interface {
+	isCause_Kind()
+}
+
+type Cause_Text struct {
+	// Text of an error other than sperror.Error.
+	Text string `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type Cause_Error struct {
+	Error *Error `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*Cause_Text) isCause_Kind() {}
+
+func (*Cause_Error) isCause_Kind() {}
+
+// Frame is a single call of a stack trace.
+type Frame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Function      string                 `protobuf:"bytes,1,opt,name=function,proto3" json:"function,omitempty"`
+	File          string                 `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	mi := &file_sperror_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Frame) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *Frame) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Frame) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+// FieldError is the error of a single field of a group.
+type FieldError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Error         *Error                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldError) Reset() {
+	*x = FieldError{}
+	mi := &file_sperror_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldError) ProtoMessage() {}
+
+func (x *FieldError) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldError.ProtoReflect.Descriptor instead.
+func (*FieldError) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FieldError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldError) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// Retry is the retry classification of a layer.
+type Retry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Retryable     bool                   `protobuf:"varint,1,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	Temporary     bool                   `protobuf:"varint,2,opt,name=temporary,proto3" json:"temporary,omitempty"`
+	Timeout       bool                   `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	RetryAfterMs  int64                  `protobuf:"varint,4,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Retry) Reset() {
+	*x = Retry{}
+	mi := &file_sperror_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Retry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Retry) ProtoMessage() {}
+
+func (x *Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
+func (*Retry) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Retry) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *Retry) GetTemporary() bool {
+	if x != nil {
+		return x.Temporary
+	}
+	return false
+}
+
+func (x *Retry) GetTimeout() bool {
+	if x != nil {
+		return x.Timeout
+	}
+	return false
+}
+
+func (x *Retry) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+// Value is a meta value. Unlike google.protobuf.Value, it keeps integers apart from floating point numbers.
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Value_NullValue
+	//	*Value_BoolValue
+	//	*Value_IntValue
+	//	*Value_UintValue
+	//	*Value_DoubleValue
+	//	*Value_StringValue
+	//	*Value_BytesValue
+	//	*Value_ListValue
+	//	*Value_StructValue
+	Kind          isValue_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_sperror_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Value) GetKind() isValue_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetNullValue() NullValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_NullValue); ok {
+			return x.NullValue
+		}
+	}
+	return NullValue_NULL_VALUE
+}
+
+func (x *Value) GetBoolValue() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BoolValue); ok {
+			return x.BoolValue
+		}
+	}
+	return false
+}
+
+func (x *Value) GetIntValue() int64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetUintValue() uint64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_UintValue); ok {
+			return x.UintValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetDoubleValue() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_DoubleValue); ok {
+			return x.DoubleValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetBytesValue() []byte {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BytesValue); ok {
+			return x.BytesValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetListValue() *ListValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_ListValue); ok {
+			return x.ListValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetStructValue() *Struct {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StructValue); ok {
+			return x.StructValue
+		}
+	}
+	return nil
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_NullValue struct {
+	NullValue NullValue `protobuf:"varint,1,opt,name=null_value,json=nullValue,proto3,enum=lighthouse.sperror.v1.NullValue,oneof"`
+}
+
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,2,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,3,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Value_UintValue struct {
+	UintValue uint64 `protobuf:"varint,4,opt,name=uint_value,json=uintValue,proto3,oneof"`
+}
+
+type Value_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,5,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,6,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Value_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,7,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+type Value_ListValue struct {
+	ListValue *ListValue `protobuf:"bytes,8,opt,name=list_value,json=listValue,proto3,oneof"`
+}
+
+type Value_StructValue struct {
+	StructValue *Struct `protobuf:"bytes,9,opt,name=struct_value,json=structValue,proto3,oneof"`
+}
+
+func (*Value_NullValue) isValue_Kind() {}
+
+func (*Value_BoolValue) isValue_Kind() {}
+
+func (*Value_IntValue) isValue_Kind() {}
+
+func (*Value_UintValue) isValue_Kind() {}
+
+func (*Value_DoubleValue) isValue_Kind() {}
+
+func (*Value_StringValue) isValue_Kind() {}
+
+func (*Value_BytesValue) isValue_Kind() {}
+
+func (*Value_ListValue) isValue_Kind() {}
+
+func (*Value_StructValue) isValue_Kind() {}
+
+// ListValue is a list of meta values.
+type ListValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []*Value               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListValue) Reset() {
+	*x = ListValue{}
+	mi := &file_sperror_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListValue) ProtoMessage() {}
+
+func (x *ListValue) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListValue.ProtoReflect.Descriptor instead.
+func (*ListValue) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListValue) GetValues() []*Value {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// Struct is a map of meta values.
+type Struct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        map[string]*Value      `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Struct) Reset() {
+	*x = Struct{}
+	mi := &file_sperror_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Struct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Struct) ProtoMessage() {}
+
+func (x *Struct) ProtoReflect() protoreflect.Message {
+	mi := &file_sperror_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Struct.ProtoReflect.Descriptor instead.
+func (*Struct) Descriptor() ([]byte, []int) {
+	return file_sperror_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Struct) GetFields() map[string]*Value {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+var File_sperror_proto protoreflect.FileDescriptor
+
+const file_sperror_proto_rawDesc = "" +
+	"\n" +
+	"\rsperror.proto\x12\x15lighthouse.sperror.v1\"=\n" +
+	"\x05Error\x124\n" +
+	"\x06layers\x18\x01 \x03(\v2\x1c.lighthouse.sperror.v1.LayerR\x06layers\"\x97\x05\n" +
+	"\x05Layer\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12F\n" +
+	"\bmessages\x18\x02 \x03(\v2*.lighthouse.sperror.v1.Layer.MessagesEntryR\bmessages\x12\x12\n" +
+	"\x04desc\x18\x03 \x01(\tR\x04desc\x12\x12\n" +
+	"\x04hint\x18\x04 \x01(\tR\x04hint\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x1b\n" +
+	"\thttp_code\x18\x06 \x01(\x05R\bhttpCode\x12\x14\n" +
+	"\x05level\x18\a \x01(\rR\x05level\x12:\n" +
+	"\x04meta\x18\b \x03(\v2&.lighthouse.sperror.v1.Layer.MetaEntryR\x04meta\x122\n" +
+	"\x05cause\x18\t \x01(\v2\x1c.lighthouse.sperror.v1.CauseR\x05cause\x122\n" +
+	"\x05stack\x18\n" +
+	" \x03(\v2\x1c.lighthouse.sperror.v1.FrameR\x05stack\x12=\n" +
+	"\bchildren\x18\v \x03(\v2!.lighthouse.sperror.v1.FieldErrorR\bchildren\x122\n" +
+	"\x05retry\x18\f \x01(\v2\x1c.lighthouse.sperror.v1.RetryR\x05retry\x12\x14\n" +
+	"\x05plain\x18\r \x01(\bR\x05plain\x1a;\n" +
+	"\rMessagesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aU\n" +
+	"\tMetaEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.lighthouse.sperror.v1.ValueR\x05value:\x028\x01\"[\n" +
+	"\x05Cause\x12\x14\n" +
+	"\x04text\x18\x01 \x01(\tH\x00R\x04text\x124\n" +
+	"\x05error\x18\x02 \x01(\v2\x1c.lighthouse.sperror.v1.ErrorH\x00R\x05errorB\x06\n" +
+	"\x04kind\"K\n" +
+	"\x05Frame\x12\x1a\n" +
+	"\bfunction\x18\x01 \x01(\tR\bfunction\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\x03 \x01(\x05R\x04line\"V\n" +
+	"\n" +
+	"FieldError\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x122\n" +
+	"\x05error\x18\x02 \x01(\v2\x1c.lighthouse.sperror.v1.ErrorR\x05error\"\x83\x01\n" +
+	"\x05Retry\x12\x1c\n" +
+	"\tretryable\x18\x01 \x01(\bR\tretryable\x12\x1c\n" +
+	"\ttemporary\x18\x02 \x01(\bR\ttemporary\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\bR\atimeout\x12$\n" +
+	"\x0eretry_after_ms\x18\x04 \x01(\x03R\fretryAfterMs\"\xa7\x03\n" +
+	"\x05Value\x12A\n" +
+	"\n" +
+	"null_value\x18\x01 \x01(\x0e2 .lighthouse.sperror.v1.NullValueH\x00R\tnullValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x02 \x01(\bH\x00R\tboolValue\x12\x1d\n" +
+	"\tint_value\x18\x03 \x01(\x03H\x00R\bintValue\x12\x1f\n" +
+	"\n" +
+	"uint_value\x18\x04 \x01(\x04H\x00R\tuintValue\x12#\n" +
+	"\fdouble_value\x18\x05 \x01(\x01H\x00R\vdoubleValue\x12#\n" +
+	"\fstring_value\x18\x06 \x01(\tH\x00R\vstringValue\x12!\n" +
+	"\vbytes_value\x18\a \x01(\fH\x00R\n" +
+	"bytesValue\x12A\n" +
+	"\n" +
+	"list_value\x18\b \x01(\v2 .lighthouse.sperror.v1.ListValueH\x00R\tlistValue\x12B\n" +
+	"\fstruct_value\x18\t \x01(\v2\x1d.lighthouse.sperror.v1.StructH\x00R\vstructValueB\x06\n" +
+	"\x04kind\"A\n" +
+	"\tListValue\x124\n" +
+	"\x06values\x18\x01 \x03(\v2\x1c.lighthouse.sperror.v1.ValueR\x06values\"\xa4\x01\n" +
+	"\x06Struct\x12A\n" +
+	"\x06fields\x18\x01 \x03(\v2).lighthouse.sperror.v1.Struct.FieldsEntryR\x06fields\x1aW\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.lighthouse.sperror.v1.ValueR\x05value:\x028\x01*\x1b\n" +
+	"\tNullValue\x12\x0e\n" +
+	"\n" +
+	"NULL_VALUE\x10\x00B1Z/github.com/s4bb4t/lighthouse/pkg/core/export/pbb\x06proto3"
+
+var (
+	file_sperror_proto_rawDescOnce sync.Once
+	file_sperror_proto_rawDescData []byte
+)
+
+func file_sperror_proto_rawDescGZIP() []byte {
+	file_sperror_proto_rawDescOnce.Do(func() {
+		file_sperror_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sperror_proto_rawDesc), len(file_sperror_proto_rawDesc)))
+	})
+	return file_sperror_proto_rawDescData
+}
+
+var file_sperror_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sperror_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_sperror_proto_goTypes = []any{
+	(NullValue)(0),     // 0: lighthouse.sperror.v1.NullValue
+	(*Error)(nil),      // 1: lighthouse.sperror.v1.Error
+	(*Layer)(nil),      // 2: lighthouse.sperror.v1.Layer
+	(*Cause)(nil),      // 3: lighthouse.sperror.v1.Cause
+	(*Frame)(nil),      // 4: lighthouse.sperror.v1.Frame
+	(*FieldError)(nil), // 5: lighthouse.sperror.v1.FieldError
+	(*Retry)(nil),      // 6: lighthouse.sperror.v1.Retry
+	(*Value)(nil),      // 7: lighthouse.sperror.v1.Value
+	(*ListValue)(nil),  // 8: lighthouse.sperror.v1.ListValue
+	(*Struct)(nil),     // 9: lighthouse.sperror.v1.Struct
+	nil,                // 10: lighthouse.sperror.v1.Layer.MessagesEntry
+	nil,                // 11: lighthouse.sperror.v1.Layer.MetaEntry
+	nil,                // 12: lighthouse.sperror.v1.Struct.FieldsEntry
+}
+var file_sperror_proto_depIdxs = []int32{
+	2,  // 0: lighthouse.sperror.v1.Error.layers:type_name -> lighthouse.sperror.v1.Layer
+	10, // 1: lighthouse.sperror.v1.Layer.messages:type_name -> lighthouse.sperror.v1.Layer.MessagesEntry
+	11, // 2: lighthouse.sperror.v1.Layer.meta:type_name -> lighthouse.sperror.v1.Layer.MetaEntry
+	3,  // 3: lighthouse.sperror.v1.Layer.cause:type_name -> lighthouse.sperror.v1.Cause
+	4,  // 4: lighthouse.sperror.v1.Layer.stack:type_name -> lighthouse.sperror.v1.Frame
+	5,  // 5: lighthouse.sperror.v1.Layer.children:type_name -> lighthouse.sperror.v1.FieldError
+	6,  // 6: lighthouse.sperror.v1.Layer.retry:type_name -> lighthouse.sperror.v1.Retry
+	1,  // 7: lighthouse.sperror.v1.Cause.error:type_name -> lighthouse.sperror.v1.Error
+	1,  // 8: lighthouse.sperror.v1.FieldError.error:type_name -> lighthouse.sperror.v1.Error
+	0,  // 9: lighthouse.sperror.v1.Value.null_value:type_name -> lighthouse.sperror.v1.NullValue
+	8,  // 10: lighthouse.sperror.v1.Value.list_value:type_name -> lighthouse.sperror.v1.ListValue
+	9,  // 11: lighthouse.sperror.v1.Value.struct_value:type_name -> lighthouse.sperror.v1.Struct
+	7,  // 12: lighthouse.sperror.v1.ListValue.values:type_name -> lighthouse.sperror.v1.Value
+	12, // 13: lighthouse.sperror.v1.Struct.fields:type_name -> lighthouse.sperror.v1.Struct.FieldsEntry
+	7,  // 14: lighthouse.sperror.v1.Layer.MetaEntry.value:type_name -> lighthouse.sperror.v1.Value
+	7,  // 15: lighthouse.sperror.v1.Struct.FieldsEntry.value:type_name -> lighthouse.sperror.v1.Value
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_sperror_proto_init() }
+func file_sperror_proto_init() {
+	if File_sperror_proto != nil {
+		return
+	}
+	file_sperror_proto_msgTypes[2].OneofWrappers = []any{
+		(*Cause_Text)(nil),
+		(*Cause_Error)(nil),
+	}
+	file_sperror_proto_msgTypes[6].OneofWrappers = []any{
+		(*Value_NullValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_UintValue)(nil),
+		(*Value_DoubleValue)(nil),
+		(*Value_StringValue)(nil),
+		(*Value_BytesValue)(nil),
+		(*Value_ListValue)(nil),
+		(*Value_StructValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sperror_proto_rawDesc), len(file_sperror_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_sperror_proto_goTypes,
+		DependencyIndexes: file_sperror_proto_depIdxs,
+		EnumInfos:         file_sperror_proto_enumTypes,
+		MessageInfos:      file_sperror_proto_msgTypes,
+	}.Build()
+	File_sperror_proto = out.File
+	file_sperror_proto_goTypes = nil
+	file_sperror_proto_depIdxs = nil
+}
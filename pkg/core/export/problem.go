@@ -0,0 +1,228 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+)
+
+// ProblemJson is the media type of RFC 9457 (formerly RFC 7807) Problem Details documents.
+const ProblemJson = "application/problem+json"
+
+// ProblemTypeBase is the URI prepended to the problem type of documents rendered by Problem.
+// The type is built as ProblemTypeBase + "/" + slug of the HTTP status text, e.g. "https://errors.example.com/not-found".
+// If it is empty, the type is "about:blank", as RFC 9457 suggests for problems without extra semantics.
+var ProblemTypeBase = ""
+
+// members defined by RFC 9457, meta never overrides them.
+var problemMembers = map[string]struct{}{
+	"type":     {},
+	"title":    {},
+	"status":   {},
+	"detail":   {},
+	"instance": {},
+	"hint":     {},
+	"code":     {},
+	"errors":   {},
+}
+
+// Problem renders e as an application/problem+json document.
+//
+// The error is spun to levels.LevelUser first, so internals are never exposed.
+// If the outer layer is not user-facing at all, only its HTTP status is rendered.
+// The title is the message for lang (see sperror.Error.MsgFor) or the HTTP status text if there are no messages.
+// Detail is the description, while the hint, the error code and meta are added as extension members.
+// String meta values "type" and "instance" are used as the corresponding members.
+// Field errors of an error built by sperror.Group are rendered as the "errors" list of field, message, detail and code.
+// A nil error is not rendered and an error is returned instead.
+//
+// Problem documents are sent to clients, so the error is redacted with sperror.DefaultPolicy;
+// Options.Problem applies Options.Redact instead.
+func Problem(e *sperror.Error, lang string) ([]byte, error) {
+	return Options{Redact: sperror.DefaultPolicy()}.Problem(e, lang)
+}
+
+// Problem renders e as an application/problem+json document redacted with Options.Redact, see Problem.
+func (o Options) Problem(e *sperror.Error, lang string) ([]byte, error) {
+	if e == nil {
+		return nil, sperror.New(sperror.Sample{
+			Messages: map[string]string{
+				sperror.En: "Nothing to render",
+			},
+			Desc:     "Problem is called with a nil error",
+			Hint:     "Render problems only for non-nil errors",
+			HttpCode: http.StatusInternalServerError,
+			Level:    levels.LevelError,
+		})
+	}
+	e = e.Redact(o.Redact)
+	if e.Level() > levels.LevelUser {
+		e = sperror.NewSpErr().SetCode(e.Code())
+	} else {
+		e = e.Spin(levels.LevelUser)
+	}
+
+	doc := make(map[string]any)
+	for k, v := range e.AllMeta() {
+		if _, ok := problemMembers[k]; !ok {
+			doc[k] = v
+		}
+	}
+
+	status := e.Code()
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	doc["status"] = status
+
+	title := e.MsgFor(lang)
+	if title == "" {
+		title = http.StatusText(status)
+	}
+	doc["title"] = title
+
+	doc["type"] = problemType(e, status)
+	if instance, ok := e.Meta("instance").(string); ok {
+		doc["instance"] = instance
+	}
+	if e.Desc() != "" {
+		doc["detail"] = e.Desc()
+	}
+	if e.Hint() != "" {
+		doc["hint"] = e.Hint()
+	}
+	if e.ErrCode() != "" {
+		doc["code"] = e.ErrCode()
+	}
+	if children := e.Children(); len(children) != 0 {
+		list := make([]map[string]any, len(children))
+		for i, c := range children {
+			item := map[string]any{"field": c.Field, "message": c.Err.MsgFor(lang)}
+			if c.Err.Desc() != "" {
+				item["detail"] = c.Err.Desc()
+			}
+			if c.Err.ErrCode() != "" {
+				item["code"] = c.Err.ErrCode()
+			}
+			list[i] = item
+		}
+		doc["errors"] = list
+	}
+
+	return json.Marshal(doc)
+}
+
+// ParseProblem turns a Problem Details document back into an *Error.
+//
+// lang is the language of the title, usually taken from the Content-Language header; English is used if it is empty.
+// Status, detail, hint and code become the HTTP code, description, hint and error code, while type, instance and
+// extension members are stored in meta. The resulting error has levels.LevelUser.
+// Remote texts are plain text (see sperror.Sample.Plain), so they are kept as is and never filled from meta.
+func ParseProblem(data []byte, lang string) (*sperror.Error, error) {
+	var doc struct {
+		Status int    `json:"status"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+		Hint   string `json:"hint"`
+		Code   string `json:"code"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, invalidProblem(err)
+	}
+
+	var ext map[string]any
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return nil, invalidProblem(err)
+	}
+	for _, k := range []string{"status", "title", "detail", "hint", "code"} {
+		delete(ext, k)
+	}
+	// "about:blank" is the default type, so it carries no information
+	if ext["type"] == "about:blank" {
+		delete(ext, "type")
+	}
+
+	if lang == "" {
+		lang = sperror.En
+	}
+	if doc.Title == "" {
+		doc.Title = http.StatusText(doc.Status)
+	}
+
+	return sperror.HelperNew(sperror.Sample{
+		Code:     doc.Code,
+		Messages: map[string]string{lang: doc.Title},
+		Desc:     doc.Detail,
+		Hint:     doc.Hint,
+		Plain:    true,
+		HttpCode: doc.Status,
+		Level:    levels.LevelUser,
+		Meta:     ext,
+	}), nil
+}
+
+// ParseProblemResponse reads a Problem Details document from the response body.
+// The title language is taken from the Content-Language header, the retry delay from the Retry-After header in seconds.
+// It returns an error if the response is not application/problem+json.
+func ParseProblemResponse(resp *http.Response) (*sperror.Error, error) {
+	mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mt != ProblemJson {
+		return nil, sperror.New(sperror.Sample{
+			Messages: map[string]string{
+				sperror.En: "Unexpected content type",
+			},
+			Desc:     "Response is not an " + ProblemJson + " document",
+			Hint:     "Check that the remote service renders errors as Problem Details",
+			HttpCode: http.StatusBadGateway,
+			Level:    levels.LevelError,
+			Cause:    err,
+			Meta:     map[string]any{"content_type": resp.Header.Get("Content-Type")},
+		})
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, invalidProblem(err)
+	}
+
+	lang, _, _ := strings.Cut(resp.Header.Get("Content-Language"), ",")
+	e, err := ParseProblem(data, strings.TrimSpace(lang))
+	if err != nil {
+		return nil, err
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		e = e.SetRetryAfter(time.Duration(secs) * time.Second)
+	}
+	return e, nil
+}
+
+func problemType(e *sperror.Error, status int) string {
+	if t, ok := e.Meta("type").(string); ok && t != "" {
+		return t
+	}
+	if ProblemTypeBase == "" {
+		return "about:blank"
+	}
+	slug := strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "-"))
+	return strings.TrimSuffix(ProblemTypeBase, "/") + "/" + slug
+}
+
+func invalidProblem(err error) *sperror.Error {
+	return sperror.New(sperror.Sample{
+		Messages: map[string]string{
+			sperror.En: "Invalid problem document",
+		},
+		Desc:     "Failed to decode " + ProblemJson + " document",
+		Hint:     "Check the response body of the remote service",
+		HttpCode: http.StatusBadGateway,
+		Level:    levels.LevelError,
+		Cause:    err,
+	})
+}
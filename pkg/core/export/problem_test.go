@@ -0,0 +1,241 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProblem(t *testing.T) {
+	notFound := sperror.New(sperror.Sample{
+		Code: "users.not_found",
+		Messages: map[string]string{
+			sperror.En: "Not found",
+			sperror.Ru: "Не найдено",
+		},
+		Desc:     "User not found",
+		Hint:     "Check user id",
+		HttpCode: http.StatusNotFound,
+		Level:    levels.LevelUser,
+		Meta:     map[string]any{"user_id": 42, "title": "ignored"},
+	})
+
+	tests := []struct {
+		name string
+		base string
+		err  *sperror.Error
+		lang string
+		want map[string]any
+	}{
+		{
+			name: "user error",
+			err:  notFound,
+			lang: sperror.Ru,
+			want: map[string]any{
+				"type":    "about:blank",
+				"title":   "Не найдено",
+				"status":  float64(404),
+				"detail":  "User not found",
+				"hint":    "Check user id",
+				"code":    "users.not_found",
+				"user_id": float64(42),
+			},
+		},
+		{
+			name: "type base and language fallback",
+			base: "https://errors.example.com/",
+			err:  notFound,
+			lang: sperror.De,
+			want: map[string]any{
+				"type":    "https://errors.example.com/not-found",
+				"title":   "Not found",
+				"status":  float64(404),
+				"detail":  "User not found",
+				"hint":    "Check user id",
+				"code":    "users.not_found",
+				"user_id": float64(42),
+			},
+		},
+		{
+			name: "field errors",
+			err: new(sperror.Group).
+				Add("email", sperror.BadRequest("email has no @", "Fix email").SetErrCode("users.invalid_email")).
+				Add("name", notFound).
+				Err(),
+			lang: sperror.Ru,
+			want: map[string]any{
+				"type":   "about:blank",
+				"title":  "Ошибка валидации",
+				"status": float64(400),
+				"detail": "2 field error(s): email: email has no @; name: User not found",
+				"hint":   "Fix the listed fields",
+				"errors": []any{
+					map[string]any{"field": "email", "message": "Bad request", "detail": "email has no @", "code": "users.invalid_email"},
+					map[string]any{"field": "name", "message": "Не найдено", "detail": "User not found", "code": "users.not_found"},
+				},
+			},
+		},
+		{
+			name: "redacted",
+			err: sperror.New(sperror.Sample{
+				Desc:     "Invite for bob@example.com expired",
+				HttpCode: http.StatusGone,
+				Level:    levels.LevelUser,
+				Meta:     map[string]any{"invite_token": "abc"},
+			}),
+			lang: sperror.En,
+			want: map[string]any{
+				"type":         "about:blank",
+				"title":        "Gone",
+				"status":       float64(410),
+				"detail":       "Invite for [REDACTED] expired",
+				"invite_token": "[REDACTED]",
+			},
+		},
+		{
+			name: "internal layers are hidden",
+			err:  sperror.WrapNew(sperror.Internal(errors.New("db closed"), "query failed", "check db"), sperror.Sample{Desc: "service failed", HttpCode: 503, Level: levels.LevelError}),
+			lang: sperror.En,
+			want: map[string]any{
+				"type":   "about:blank",
+				"title":  "Service Unavailable",
+				"status": float64(503),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ProblemTypeBase = tt.base
+			defer func() { ProblemTypeBase = "" }()
+
+			b, err := Problem(tt.err, tt.lang)
+			if err != nil {
+				t.Fatalf("Problem() error = %v", err)
+			}
+			var got map[string]any
+			if err = json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Problem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := Problem(nil, sperror.En); err == nil {
+		t.Errorf("Problem(nil) error = nil")
+	}
+}
+
+func TestParseProblem(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		want    *sperror.Error
+		wantErr bool
+	}{
+		{
+			name: "problem",
+			resp: &http.Response{
+				Header: http.Header{
+					"Content-Type":     {ProblemJson + "; charset=utf-8"},
+					"Content-Language": {"ru"},
+				},
+				Body: io.NopCloser(bytes.NewBufferString(`{"type":"https://errors.example.com/not-found","title":"Не найдено","status":404,"detail":"User not found","hint":"Check user id","code":"users.not_found","user_id":42}`)),
+			},
+			want: sperror.New(sperror.Sample{
+				Code:     "users.not_found",
+				Messages: map[string]string{sperror.Ru: "Не найдено"},
+				Desc:     "User not found",
+				Hint:     "Check user id",
+				HttpCode: 404,
+				Level:    levels.LevelUser,
+				Meta:     map[string]any{"type": "https://errors.example.com/not-found", "user_id": float64(42)},
+			}),
+		},
+		{
+			name: "retry after",
+			resp: &http.Response{
+				Header: http.Header{
+					"Content-Type":     {ProblemJson},
+					"Content-Language": {"ru"},
+					"Retry-After":      {"30"},
+				},
+				Body: io.NopCloser(bytes.NewBufferString(`{"title":"Слишком много запросов","status":429}`)),
+			},
+			want: sperror.New(sperror.Sample{
+				Messages:   map[string]string{sperror.Ru: "Слишком много запросов"},
+				HttpCode:   429,
+				Level:      levels.LevelUser,
+				RetryAfter: 30 * time.Second,
+			}),
+		},
+		{
+			name: "remote text is not a template",
+			resp: &http.Response{
+				Header: http.Header{"Content-Type": {ProblemJson}, "Content-Language": {"ru"}},
+				Body:   io.NopCloser(bytes.NewBufferString(`{"title":"Bad {name}","status":400,"detail":"expected {name}","hint":"{{x}}","name":"injected"}`)),
+			},
+			want: sperror.New(sperror.Sample{
+				Messages: map[string]string{sperror.Ru: sperror.EscapeTemplate("Bad {name}")},
+				Desc:     sperror.EscapeTemplate("expected {name}"),
+				Hint:     sperror.EscapeTemplate("{{x}}"),
+				HttpCode: 400,
+				Level:    levels.LevelUser,
+				Meta:     map[string]any{"name": "injected"},
+			}),
+		},
+		{
+			name: "wrong content type",
+			resp: &http.Response{
+				Header: http.Header{"Content-Type": {Json}},
+				Body:   io.NopCloser(bytes.NewBufferString(`{}`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed",
+			resp: &http.Response{
+				Header: http.Header{"Content-Type": {ProblemJson}},
+				Body:   io.NopCloser(bytes.NewBufferString(`{"status":"404"}`)),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProblemResponse(tt.resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProblemResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Msg(sperror.Ru) != tt.want.Msg(sperror.Ru) || got.Desc() != tt.want.Desc() || got.Hint() != tt.want.Hint() ||
+				got.Code() != tt.want.Code() || got.ErrCode() != tt.want.ErrCode() || got.Level() != tt.want.Level() || !reflect.DeepEqual(got.AllMeta(), tt.want.AllMeta()) ||
+				got.RetryAfter() != tt.want.RetryAfter() {
+				t.Errorf("ParseProblemResponse() = %+v, want %+v", got, tt.want)
+			}
+
+			b, err := Problem(got, sperror.Ru)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := ParseProblem(b, sperror.Ru)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again.AllMeta(), got.AllMeta()) || again.Msg(sperror.Ru) != got.Msg(sperror.Ru) {
+				t.Errorf("round trip = %+v, want %+v", again, got)
+			}
+		})
+	}
+}
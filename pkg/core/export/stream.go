@@ -0,0 +1,524 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+)
+
+// Ndjson is the content type of newline-delimited JSON: one JSON encoded error per line.
+const Ndjson = "application/x-ndjson"
+
+type (
+	// Encoder writes errors to a stream as they arrive, without holding the whole export in memory.
+	//
+	// NDJSON streams hold a JSON document with the whole chain per line, see sperror.Error.MarshalJSON.
+	// CSV and XML streams hold the rows of Options.CSV and Options.XML, with ids running through the whole stream.
+	// Since meta keys are not known in advance, CSV streams keep meta as a JSON object in a single "meta" column,
+	// and messages in the "msg.<lang>" columns of Options.Langs, English if it is empty.
+	Encoder struct {
+		format  string
+		lvl     levels.Level
+		langs   []string
+		redact  *sperror.Policy
+		rows    int  // rows written so far, the ids of the next error continue from it
+		started bool // the CSV header or the XML root element is written
+
+		json *json.Encoder
+		csv  *csv.Writer
+		xml  *xml.Encoder
+	}
+
+	// Decoder reads errors written by Encoder back, as well as the output of JSON, CSV and XML.
+	//
+	// NDJSON restores errors exactly as encoded. CSV and XML restore what their rows hold: the chain,
+	// field errors, messages, stack traces, retry classifications and meta, with causes that match the original by text.
+	// Meta values of XML and of dotted CSV columns are restored as strings.
+	Decoder struct {
+		format string
+		next   *Error   // the first row of the next error, read ahead
+		header []string // CSV columns
+
+		json *json.Decoder
+		csv  *csv.Reader
+		xml  *xml.Decoder
+	}
+)
+
+// NewEncoder returns an Encoder with the default Options, see Options.NewEncoder.
+func NewEncoder(w io.Writer, format string) (*Encoder, error) {
+	return Options{}.NewEncoder(w, format)
+}
+
+// NewEncoder returns an Encoder writing errors to w in format: Ndjson, Csv or Xml; Json is treated as Ndjson.
+// Options.Level and Options.Redact apply to every format, Options.Langs to CSV only.
+//
+// Usage:
+//
+//	enc, err := export.NewEncoder(f, export.Ndjson)
+//	for e := range errs {
+//		if err := enc.Encode(e); err != nil {
+//			return err
+//		}
+//	}
+//	return enc.Close()
+func (o Options) NewEncoder(w io.Writer, format string) (*Encoder, error) {
+	enc := &Encoder{format: format, lvl: o.Level, langs: o.Langs, redact: o.Redact}
+	if enc.lvl == levels.LevelNoop {
+		enc.lvl = levels.LevelDebug
+	}
+	if len(enc.langs) == 0 {
+		enc.langs = []string{sperror.En}
+	}
+
+	switch format {
+	case Ndjson, Json:
+		enc.format = Ndjson
+		enc.json = json.NewEncoder(w)
+	case Csv:
+		enc.csv = csv.NewWriter(w)
+	case Xml:
+		enc.xml = xml.NewEncoder(w)
+	default:
+		return nil, unsupportedFormat(format)
+	}
+	return enc, nil
+}
+
+// Encode writes e to the stream, redacted with Options.Redact. Nil errors and errors above Options.Level are skipped,
+// layers above Options.Level are cut from the chain.
+func (enc *Encoder) Encode(e *sperror.Error) error {
+	if e == nil || e.Level() > enc.lvl {
+		return nil
+	}
+	e = e.Redact(enc.redact)
+
+	if enc.format == Ndjson {
+		return enc.json.Encode(trim(e, enc.lvl))
+	}
+	if err := enc.start(); err != nil {
+		return err
+	}
+
+	rows := appendRows(nil, e, 0, "", enc.lvl, enc.langs)
+	for i := range rows {
+		rows[i].ID += enc.rows
+		if rows[i].Parent != 0 {
+			rows[i].Parent += enc.rows
+		}
+	}
+	enc.rows += len(rows)
+
+	if enc.csv != nil {
+		for _, r := range rows {
+			if err := enc.csv.Write(enc.record(r)); err != nil {
+				return err
+			}
+		}
+		enc.csv.Flush()
+		return enc.csv.Error()
+	}
+	for _, r := range rows {
+		if err := enc.xml.Encode(r); err != nil {
+			return err
+		}
+	}
+	return enc.xml.Flush()
+}
+
+// Close completes the stream: it writes the CSV header of an empty stream or closes the XML root element.
+// It does not close the underlying writer.
+func (enc *Encoder) Close() error {
+	if enc.format == Ndjson {
+		return nil
+	}
+	if err := enc.start(); err != nil {
+		return err
+	}
+	if enc.csv != nil {
+		enc.csv.Flush()
+		return enc.csv.Error()
+	}
+	if err := enc.xml.EncodeToken(xml.EndElement{Name: xml.Name{Local: "errors"}}); err != nil {
+		return err
+	}
+	return enc.xml.Flush()
+}
+
+// start writes the CSV header or the XML root element once.
+func (enc *Encoder) start() error {
+	if enc.started {
+		return nil
+	}
+	enc.started = true
+
+	if enc.csv != nil {
+		header := slices.Clone(columns)
+		for _, lg := range enc.langs {
+			header = append(header, "msg."+lg)
+		}
+		return enc.csv.Write(append(header, "desc", "hint", "source", "cause", "stack", "retry", "meta"))
+	}
+	return enc.xml.EncodeToken(xml.StartElement{Name: xml.Name{Local: "errors"}})
+}
+
+// record returns the CSV record of r.
+func (enc *Encoder) record(r Error) []string {
+	rec := []string{
+		strconv.Itoa(r.ID), itoa(r.Parent), strconv.Itoa(r.Layer), r.Field, r.Code, itoa(r.HttpCode), r.Level,
+	}
+	for _, m := range r.Messages {
+		rec = append(rec, m.Text)
+	}
+	rec = append(rec, r.Desc, r.Hint, r.Source, r.Cause, r.Stack, r.Retry)
+
+	var meta string
+	if len(r.meta) != 0 {
+		// values that fail to encode are lost rather than failing the whole stream
+		data, _ := json.Marshal(r.meta)
+		meta = string(data)
+	}
+	return append(rec, meta)
+}
+
+// NewDecoder returns a Decoder reading errors from r in format: Ndjson, Csv or Xml; Json is treated as Ndjson.
+func NewDecoder(r io.Reader, format string) (*Decoder, error) {
+	dec := &Decoder{format: format}
+	switch format {
+	case Ndjson, Json:
+		dec.format = Ndjson
+		dec.json = json.NewDecoder(r)
+	case Csv:
+		dec.csv = csv.NewReader(r)
+		dec.csv.FieldsPerRecord = -1
+	case Xml:
+		dec.xml = xml.NewDecoder(r)
+	default:
+		return nil, unsupportedFormat(format)
+	}
+	return dec, nil
+}
+
+// Decode reads the next error of the stream. It returns io.EOF at the end of the stream.
+func (dec *Decoder) Decode() (*sperror.Error, error) {
+	if dec.format == Ndjson {
+		e := &sperror.Error{}
+		if err := dec.json.Decode(e); err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, invalidStream(err)
+		}
+		return e, nil
+	}
+
+	// an error spans the rows from its outer layer up to the next row without a parent
+	first := dec.next
+	dec.next = nil
+	if first == nil {
+		var err error
+		if first, err = dec.row(); err != nil {
+			return nil, err
+		}
+	}
+	rows := []*Error{first}
+	for {
+		r, err := dec.row()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.Parent == 0 {
+			dec.next = r
+			break
+		}
+		rows = append(rows, r)
+	}
+
+	e, err := restore(first, rows, make(map[int]bool, len(rows)))
+	if err != nil {
+		return nil, invalidStream(err)
+	}
+	return e, nil
+}
+
+// All returns an iterator over the errors of the stream. It stops after the first error of Decode other than io.EOF.
+func (dec *Decoder) All() iter.Seq2[*sperror.Error, error] {
+	return func(yield func(*sperror.Error, error) bool) {
+		for {
+			e, err := dec.Decode()
+			if err == io.EOF {
+				return
+			}
+			if !yield(e, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// row reads the next CSV or XML row. Meta of the row is restored to Error.meta.
+func (dec *Decoder) row() (*Error, error) {
+	if dec.csv != nil {
+		return dec.csvRow()
+	}
+
+	for {
+		tok, err := dec.xml.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, invalidStream(err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "error" {
+			continue
+		}
+		r := &Error{}
+		if err = dec.xml.DecodeElement(r, &start); err != nil {
+			return nil, invalidStream(err)
+		}
+		r.meta = unflatten(r.Meta)
+		return r, nil
+	}
+}
+
+func (dec *Decoder) csvRow() (*Error, error) {
+	if dec.header == nil {
+		header, err := dec.csv.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, invalidStream(err)
+		}
+		dec.header = header
+	}
+
+	rec, err := dec.csv.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, invalidStream(err)
+	}
+
+	r := &Error{meta: make(map[string]any)}
+	for i, col := range dec.header {
+		if i >= len(rec) || rec[i] == "" {
+			continue
+		}
+		v := rec[i]
+		switch col {
+		case "id":
+			r.ID, err = strconv.Atoi(v)
+		case "parent":
+			r.Parent, err = strconv.Atoi(v)
+		case "layer":
+			r.Layer, err = strconv.Atoi(v)
+		case "field":
+			r.Field = v
+		case "code":
+			r.Code = v
+		case "http_code":
+			r.HttpCode, err = strconv.Atoi(v)
+		case "level":
+			r.Level = v
+		case "desc":
+			r.Desc = v
+		case "hint":
+			r.Hint = v
+		case "source":
+			r.Source = v
+		case "cause":
+			r.Cause = v
+		case "stack":
+			r.Stack = v
+		case "retry":
+			r.Retry = v
+		case "meta":
+			err = json.Unmarshal([]byte(v), &r.meta)
+		default:
+			if lg, ok := strings.CutPrefix(col, "msg."); ok {
+				r.Messages = append(r.Messages, Message{Lang: lg, Text: v})
+			} else if key, ok := strings.CutPrefix(col, "meta."); ok {
+				set(r.meta, strings.Split(key, "."), v)
+			}
+		}
+		if err != nil {
+			return nil, invalidStream(err)
+		}
+	}
+	return r, nil
+}
+
+// restore builds the error whose outer layer is r from the rows of the error.
+// Rows hold rendered texts, so they are restored as plain text rather than filled from meta again.
+// seen holds the ids of the rows on the way from the first one, a row met twice is a malformed stream.
+func restore(r *Error, rows []*Error, seen map[int]bool) (*sperror.Error, error) {
+	if seen[r.ID] {
+		return nil, fmt.Errorf("row %d is a parent of itself", r.ID)
+	}
+	seen[r.ID] = true
+
+	var (
+		children   []sperror.FieldError
+		underlying *sperror.Error
+	)
+	for _, c := range rows {
+		if c.Parent != r.ID || c.Field == "" && underlying != nil {
+			continue
+		}
+		e, err := restore(c, rows, seen)
+		if err != nil {
+			return nil, err
+		}
+		if c.Field != "" {
+			children = append(children, sperror.FieldError{Field: c.Field, Err: e})
+		} else {
+			underlying = e
+		}
+	}
+
+	lvl, _ := strconv.Atoi(r.Level)
+	s := sperror.Sample{
+		Code:     r.Code,
+		Messages: make(map[string]string, len(r.Messages)),
+		Desc:     r.Desc,
+		Hint:     r.Hint,
+		Plain:    true,
+		HttpCode: r.HttpCode,
+		Level:    levels.Level(lvl),
+		Meta:     r.meta,
+	}
+	for _, m := range r.Messages {
+		if m.Text != "" {
+			s.Messages[m.Lang] = m.Text
+		}
+	}
+	if r.Cause != "" {
+		s.Cause = sperror.TextError(r.Cause)
+	}
+
+	e := sperror.Restore(s, r.Source, parseStack(r.Stack), children...)
+	if r.Retry != "" {
+		c, err := parseRetry(r.Retry)
+		if err != nil {
+			return nil, err
+		}
+		e = e.SetRetryClass(c)
+	}
+	if underlying != nil {
+		e = sperror.Wrap(underlying, e)
+	}
+	return e, nil
+}
+
+// trim cuts the chain of e at the first layer above lvl, like the rows of CSV and XML.
+func trim(e *sperror.Error, lvl levels.Level) *sperror.Error {
+	var kept []*sperror.Error
+	for _, layer := range e.Layers() {
+		if layer.Level() > lvl {
+			break
+		}
+		kept = append(kept, layer)
+	}
+	if len(kept) == e.Depth() {
+		return e
+	}
+
+	// Spin detaches the innermost kept layer, the outer ones are shallow copies wrapped around it again
+	res := e.Spin(lvl)
+	for i := len(kept) - 2; i >= 0; i-- {
+		layer := *kept[i]
+		res = sperror.Wrap(res, &layer)
+	}
+	return res
+}
+
+// parseStack parses the output of sperror.StackTrace.String.
+func parseStack(s string) sperror.StackTrace {
+	if s == "" {
+		return nil
+	}
+	var st sperror.StackTrace
+	lines := strings.Split(s, "\n")
+	for i := 0; i+1 < len(lines); i += 2 {
+		f := sperror.Frame{Function: lines[i], File: strings.TrimPrefix(lines[i+1], "\t")}
+		if j := strings.LastIndexByte(f.File, ':'); j >= 0 {
+			f.Line, _ = strconv.Atoi(f.File[j+1:])
+			f.File = f.File[:j]
+		}
+		st = append(st, f)
+	}
+	return st
+}
+
+// unflatten converts meta entries back to a map, nested entries to nested maps.
+func unflatten(entries []Meta) map[string]any {
+	if len(entries) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(entries))
+	for _, e := range entries {
+		if e.Entries != nil {
+			m[e.Key] = unflatten(e.Entries)
+			continue
+		}
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+// set stores v in m under the dotted path, creating nested maps on the way.
+func set(m map[string]any, path []string, v string) {
+	for _, k := range path[:len(path)-1] {
+		nested, ok := m[k].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			m[k] = nested
+		}
+		m = nested
+	}
+	m[path[len(path)-1]] = v
+}
+
+func unsupportedFormat(format string) *sperror.Error {
+	return sperror.New(sperror.Sample{
+		Messages: map[string]string{
+			sperror.En: "Unsupported export format",
+		},
+		Desc:     "Format " + format + " is not supported by export streams",
+		Hint:     "Use export.Ndjson, export.Csv or export.Xml",
+		Plain:    true,
+		HttpCode: http.StatusInternalServerError,
+		Level:    levels.LevelError,
+		Meta:     map[string]any{"format": format},
+	})
+}
+
+func invalidStream(err error) *sperror.Error {
+	return sperror.New(sperror.Sample{
+		Messages: map[string]string{
+			sperror.En: "Invalid export stream",
+		},
+		Desc:     "Failed to decode exported errors",
+		Hint:     "Check that the stream was written by export.Encoder in the same format",
+		HttpCode: http.StatusInternalServerError,
+		Level:    levels.LevelError,
+		Cause:    err,
+	})
+}
@@ -0,0 +1,343 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleEncoder() {
+	var buf bytes.Buffer
+	enc, _ := NewEncoder(&buf, Ndjson)
+	for id := range 2 {
+		_ = enc.Encode(sperror.New(sperror.Sample{Code: "users.not_found", Desc: "User not found", Meta: map[string]any{"id": id}}))
+	}
+	_ = enc.Close()
+
+	dec, _ := NewDecoder(&buf, Ndjson)
+	for e, err := range dec.All() {
+		fmt.Println(e.ErrCode(), e.Meta("id"), err)
+	}
+	// Output:
+	// users.not_found 0 <nil>
+	// users.not_found 1 <nil>
+}
+
+func TestStream(t *testing.T) {
+	group := new(sperror.Group).
+		Add("email", sperror.New(sperror.Sample{Code: "email.invalid", Desc: "invalid email", Level: levels.LevelUser})).
+		Err()
+	debug := sperror.New(sperror.Sample{Code: "debug", Desc: "internal", Level: levels.LevelDebug})
+
+	// flat describes a decoded error by its layers and field errors
+	flat := func(e *sperror.Error) []string {
+		var res []string
+		for _, layer := range e.Layers() {
+			res = append(res, fmt.Sprintf("%s %d %d %q %q %v", layer.ErrCode(), layer.Code(), layer.Level(),
+				layer.Msg(sperror.En), layer.Desc(), layer.AllMeta()))
+			for _, c := range layer.Children() {
+				res = append(res, c.Field+": "+c.Err.ErrCode())
+			}
+		}
+		return res
+	}
+
+	all := [][]string{
+		{
+			`users.not_found 404 2 "Not found" "User not found" map[token:secret user_id:42]`,
+			`users.db 0 255 "Storage failed" "query failed" map[request:map[method:GET path:/users/42]]`,
+		},
+		{` 400 2 "Validation failed" "1 field error(s): email: invalid email" map[]`, "email: email.invalid"},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+		errs   []*sperror.Error
+		want   [][]string
+	}{
+		{
+			name:   "ndjson",
+			format: Ndjson,
+			errs:   []*sperror.Error{chain(), nil, group},
+			want:   all,
+		},
+		{
+			name:   "csv",
+			format: Csv,
+			errs:   []*sperror.Error{chain(), group},
+			want:   all,
+		},
+		{
+			name:   "xml",
+			format: Xml,
+			errs:   []*sperror.Error{chain(), group},
+			want:   all,
+		},
+		{
+			name:   "level",
+			format: Csv,
+			opts:   Options{Level: levels.LevelError, Redact: sperror.DefaultPolicy()},
+			errs:   []*sperror.Error{debug, chain()},
+			want: [][]string{
+				{`users.not_found 404 2 "Not found" "User not found" map[token:[REDACTED] user_id:42]`},
+			},
+		},
+		{
+			name:   "empty csv",
+			format: Csv,
+		},
+		{
+			name:   "empty xml",
+			format: Xml,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := tt.opts.NewEncoder(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("NewEncoder() error = %v", err)
+			}
+			for _, e := range tt.errs {
+				if err = enc.Encode(e); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+			}
+			if err = enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			dec, err := NewDecoder(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("NewDecoder() error = %v", err)
+			}
+			var got [][]string
+			for e, err := range dec.All() {
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				got = append(got, flat(e))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStream_CSVIds(t *testing.T) {
+	var buf bytes.Buffer
+	enc, _ := NewEncoder(&buf, Csv)
+	for range 2 {
+		if err := enc.Encode(chain()); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var ids []string
+	for _, r := range records(t, buf.Bytes()) {
+		ids = append(ids, r["id"]+"<"+r["parent"])
+	}
+	if want := []string{"1<", "2<1", "3<", "4<3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+}
+
+func TestDecoder_Export(t *testing.T) {
+	e := sperror.WrapNew(sperror.New(sperror.Sample{
+		Code:  "users.db",
+		Desc:  "query failed",
+		Level: levels.LevelDebug,
+		Cause: io.ErrUnexpectedEOF,
+	}).SetStack(), sperror.Sample{Code: "users.failed", HttpCode: http.StatusInternalServerError, Level: levels.LevelError})
+
+	for _, format := range []string{Csv, Xml} {
+		t.Run(format, func(t *testing.T) {
+			exp := CSV
+			if format == Xml {
+				exp = XML
+			}
+			data, err := exp(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, _ := NewDecoder(bytes.NewReader(data), format)
+			got, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !errors.Is(got, io.ErrUnexpectedEOF) {
+				t.Errorf("Decode() cause = %v, want %v", got.Root().Caused(), io.ErrUnexpectedEOF)
+			}
+			if st, want := got.Root().StackTrace(), e.Root().StackTrace(); !reflect.DeepEqual(st, want) {
+				t.Errorf("Decode() stack = %v, want %v", st, want)
+			}
+			if got.Source() != e.Source() || got.Depth() != e.Depth() {
+				t.Errorf("Decode() = %v, want %v", got, e)
+			}
+			if _, err = dec.Decode(); err != io.EOF {
+				t.Errorf("Decode() at the end error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestStream_Errors(t *testing.T) {
+	if _, err := NewEncoder(io.Discard, "text/plain"); err == nil {
+		t.Errorf("NewEncoder() of an unsupported format error = nil")
+	}
+	if _, err := NewDecoder(strings.NewReader(""), "text/plain"); err == nil {
+		t.Errorf("NewDecoder() of an unsupported format error = nil")
+	}
+	if _, err := NewEncoder(io.Discard, "text/{x}"); sperror.Ensure(err).Desc() != "Format text/{x} is not supported by export streams" {
+		t.Errorf("NewEncoder() error desc = %q, want the format as is", sperror.Ensure(err).Desc())
+	}
+
+	dec, _ := NewDecoder(strings.NewReader("{\"code\":\n"), Ndjson)
+	if _, err := dec.Decode(); err == nil || err == io.EOF {
+		t.Errorf("Decode() of a broken stream error = %v", err)
+	}
+	dec, _ = NewDecoder(strings.NewReader("id,parent,level\nx,,2\n"), Csv)
+	if _, err := dec.Decode(); err == nil || err == io.EOF {
+		t.Errorf("Decode() of a broken id error = %v", err)
+	}
+
+	dec, _ = NewDecoder(strings.NewReader("id,parent,level,retry\n1,,2,soon\n"), Csv)
+	if _, err := dec.Decode(); err == nil || err == io.EOF {
+		t.Errorf("Decode() of a broken retry error = %v", err)
+	}
+
+	for name, stream := range map[string]string{
+		"self parent": "id,parent,level\n1,1,2\n",
+		"cycle":       "id,parent,level\n1,,2\n2,1,2\n1,2,2\n",
+	} {
+		dec, _ = NewDecoder(strings.NewReader(stream), Csv)
+		if _, err := dec.Decode(); err == nil || err == io.EOF {
+			t.Errorf("Decode() of a %s error = %v", name, err)
+		}
+	}
+}
+
+func TestStream_Level(t *testing.T) {
+	e := sperror.WrapNew(
+		sperror.New(sperror.Sample{Desc: "secret db", Level: levels.LevelDebug}),
+		sperror.Sample{Desc: "service failed", Level: levels.LevelUser},
+	)
+
+	for _, format := range []string{Ndjson, Csv, Xml} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, _ := Options{Level: levels.LevelUser}.NewEncoder(&buf, format)
+			if err := enc.Encode(e); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "secret db") {
+				t.Errorf("Encode() wrote a layer above the level:\n%s", buf.String())
+			}
+
+			dec, _ := NewDecoder(&buf, format)
+			got, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got.Depth() != 1 || got.Desc() != "service failed" {
+				t.Errorf("Decode() = %v, want only the user layer", got)
+			}
+		})
+	}
+	if e.Depth() != 2 {
+		t.Errorf("Encode() modified the chain of the error")
+	}
+}
+
+func TestStream_Braces(t *testing.T) {
+	e := sperror.New(sperror.Sample{
+		Messages: map[string]string{sperror.En: "Hello {name}"},
+		Desc:     "bad body {name}",
+		Hint:     "{{x}}",
+		Meta:     map[string]any{"name": "bob"},
+	})
+	foreign := sperror.Ensure(errors.New(`bad body {"a":1} {name}`))
+
+	for _, format := range []string{Csv, Xml} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, _ := NewEncoder(&buf, format)
+			_ = enc.Encode(e)
+			_ = enc.Encode(foreign)
+			_ = enc.Close()
+
+			dec, _ := NewDecoder(&buf, format)
+			for _, want := range []*sperror.Error{e, foreign} {
+				got, err := dec.Decode()
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if got.Desc() != want.Desc() || got.Hint() != want.Hint() || got.Msg(sperror.En) != want.Msg(sperror.En) {
+					t.Errorf("Decode() = %q %q %q, want %q %q %q",
+						got.Desc(), got.Hint(), got.Msg(sperror.En), want.Desc(), want.Hint(), want.Msg(sperror.En))
+				}
+			}
+		})
+	}
+}
+
+func TestStream_Retry(t *testing.T) {
+	errs := []*sperror.Error{
+		sperror.New(sperror.Sample{Desc: "slow down", RetryAfter: 1500 * time.Millisecond, Timeout: true}),
+		sperror.New(sperror.Sample{Desc: "bad request"}).SetRetryClass(sperror.RetryClass{}),
+		sperror.WrapNew(sperror.New(sperror.Sample{Desc: "busy", Temporary: true}), sperror.Sample{Desc: "failed"}),
+	}
+
+	for _, format := range []string{Ndjson, Csv, Xml} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, _ := NewEncoder(&buf, format)
+			for _, e := range errs {
+				_ = enc.Encode(e)
+			}
+			_ = enc.Close()
+
+			dec, _ := NewDecoder(&buf, format)
+			for i, want := range errs {
+				got, err := dec.Decode()
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				for j, layer := range want.Layers() {
+					gotClass, gotSet := layerAt(got, j).RetryClass()
+					wantClass, wantSet := layer.RetryClass()
+					if gotClass != wantClass || gotSet != wantSet {
+						t.Errorf("error %d layer %d RetryClass() = %+v %v, want %+v %v", i, j, gotClass, gotSet, wantClass, wantSet)
+					}
+				}
+			}
+		})
+	}
+}
+
+func layerAt(e *sperror.Error, depth int) *sperror.Error {
+	for i, layer := range e.Layers() {
+		if i == depth {
+			return layer
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/render"
+)
+
+// Markdown renders e with the default Options, see Options.Markdown.
+func Markdown(e *sperror.Error, lang string) ([]byte, error) {
+	return Options{}.Markdown(e, lang)
+}
+
+// Text renders e with the default Options, see Options.Text.
+func Text(e *sperror.Error, lang string) ([]byte, error) {
+	return Options{}.Text(e, lang)
+}
+
+// Markdown renders e as a GitHub-flavored Markdown card with messages in lang, see render.Markdown.
+func (o Options) Markdown(e *sperror.Error, lang string) ([]byte, error) {
+	return o.render(render.Markdown, e, lang)
+}
+
+// Text renders e as a plain text card with messages in lang, see render.Text.
+func (o Options) Text(e *sperror.Error, lang string) ([]byte, error) {
+	return o.render(render.Text, e, lang)
+}
+
+func (o Options) render(t render.Target, e *sperror.Error, lang string) ([]byte, error) {
+	if lang == "" {
+		lang = sperror.En
+	}
+	out, err := t.Render(e.Redact(o.Redact), lang)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
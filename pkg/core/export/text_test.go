@@ -0,0 +1,44 @@
+package export
+
+import (
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	redact := Options{Redact: sperror.DefaultPolicy()}
+	tests := []struct {
+		name   string
+		exp    func(e *sperror.Error, lang string) ([]byte, error)
+		redact func(e *sperror.Error, lang string) ([]byte, error)
+		lang   string
+		want   []string
+	}{
+		{name: "markdown", exp: Markdown, redact: redact.Markdown, want: []string{"### 🚨 Storage failed", "**Cause:** `connection reset`"}},
+		{name: "text", exp: Text, redact: redact.Text, lang: sperror.De, want: []string{"Message: Speicherfehler", "Request:\n  user_id: 42"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.exp(chain(), tt.lang)
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			got := string(data)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("%s() lacks %q:\n%s", tt.name, w, got)
+				}
+			}
+
+			e := sperror.New(sperror.Sample{Meta: map[string]any{"password": "hunter2"}})
+			if data, err = tt.exp(e, ""); err != nil || !strings.Contains(string(data), "hunter2") {
+				t.Errorf("%s() is redacted by default: %s, %v", tt.name, data, err)
+			}
+			if data, err = tt.redact(e, ""); err != nil || strings.Contains(string(data), "hunter2") {
+				t.Errorf("%s() is not redacted with Options.Redact: %s, %v", tt.name, data, err)
+			}
+		})
+	}
+}
@@ -30,8 +30,10 @@ type (
 	}
 
 	// Registry defines methods for storing and retrieving pre-defined errors.
+	// Reg returns a stable id of the registered error, Get returns a fresh copy of the error by its id.
+	// The method set replaces Get(id int) error and Reg(err error): ids are 64-bit hashes and Reg reports failures.
 	Registry interface {
-		Get(id int) error
-		Reg(err error)
+		Get(id uint64) error
+		Reg(err error) (uint64, error)
 	}
 )
@@ -1,6 +1,9 @@
 package levels
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // Level is a 2^n interpretation of the "weight" of an error.
 // It represents how severe the error is and how difficult it is to understand, handle and fix.
@@ -14,6 +17,31 @@ const (
 	LevelNoop  Level = 0 // no errors
 )
 
+var names = map[string]Level{
+	"debug": LevelDebug,
+	"error": LevelError,
+	"info":  LevelInfo,
+	"user":  LevelUser,
+	"noop":  LevelNoop,
+}
+
 func (e Level) String() string {
 	return strconv.Itoa(int(e))
 }
+
+// Name returns the name of the level accepted by Parse, e.g. "user", or an empty string for levels without a name.
+func (e Level) Name() string {
+	for name, lvl := range names {
+		if lvl == e {
+			return name
+		}
+	}
+	return ""
+}
+
+// Parse returns the level by its case-insensitive name: "debug", "error", "info", "user" or "noop".
+// The second result is false if the name is unknown.
+func Parse(name string) (Level, bool) {
+	lvl, ok := names[strings.ToLower(strings.TrimSpace(name))]
+	return lvl, ok
+}
@@ -0,0 +1,48 @@
+package levels
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   Level
+		wantOk bool
+	}{
+		{name: "user", want: LevelUser, wantOk: true},
+		{name: " Error ", want: LevelError, wantOk: true},
+		{name: "DEBUG", want: LevelDebug, wantOk: true},
+		{name: "fatal", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.name)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Parse() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestLevel_Name(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  string
+	}{
+		{level: LevelDebug, want: "debug"},
+		{level: LevelUser, want: "user"},
+		{level: LevelNoop, want: "noop"},
+		{level: 16, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := tt.level.Name(); got != tt.want {
+				t.Errorf("Name() = %q, want %q", got, tt.want)
+			}
+			if lvl, ok := Parse(tt.want); tt.want != "" && (!ok || lvl != tt.level) {
+				t.Errorf("Parse(%q) = %v, %v, want %v", tt.want, lvl, ok, tt.level)
+			}
+		})
+	}
+}
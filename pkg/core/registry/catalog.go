@@ -0,0 +1,232 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"gopkg.in/yaml.v3"
+)
+
+// Entry is a single error definition of a catalog file.
+//
+// A catalog file is a YAML or JSON list of entries:
+//
+//	# errors/users.yaml
+//	- id: user_not_found
+//	  code: users.not_found
+//	  messages:
+//	    en: User not found
+//	    ru: Пользователь не найден
+//	  desc: No user with such id
+//	  hint: Check user id
+//	  http_code: 404
+//	  level: user
+//	  meta:
+//	    kind: user
+type Entry struct {
+	ID       string            `json:"id" yaml:"id"`
+	Code     string            `json:"code,omitempty" yaml:"code,omitempty"`
+	Messages map[string]string `json:"messages" yaml:"messages"`
+	Desc     string            `json:"desc" yaml:"desc"`
+	Hint     string            `json:"hint" yaml:"hint"`
+	HttpCode int               `json:"http_code" yaml:"http_code"`
+	Level    string            `json:"level" yaml:"level"`
+	Meta     map[string]any    `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+// Sample converts the entry into a sperror.Sample.
+// The error code is the entry code or, if it is empty, the entry id.
+// Unknown levels are converted to levels.LevelNoop, use ReadCatalog to validate entries first.
+func (e Entry) Sample() sperror.Sample {
+	lvl, _ := levels.Parse(e.Level)
+	code := e.Code
+	if code == "" {
+		code = e.ID
+	}
+	return sperror.Sample{
+		Code:     code,
+		Messages: e.Messages,
+		Desc:     e.Desc,
+		Hint:     e.Hint,
+		HttpCode: e.HttpCode,
+		Level:    lvl,
+		Meta:     e.Meta,
+	}
+}
+
+// ReadCatalog reads and validates entries from files of fsys matching the glob patterns.
+// fsys can be an embed.FS, so a service can ship its catalog compiled in, or os.DirFS.
+// The format is chosen by file extension: .yaml, .yml or .json.
+//
+// Validation does not stop on the first problem: the returned error lists every missing id,
+// missing "en" message, unknown or noop level and duplicate id of all files at once.
+func ReadCatalog(fsys fs.FS, patterns ...string) ([]Entry, error) {
+	var (
+		entries  []Entry
+		problems []error
+		ids      = make(map[string]string)
+	)
+
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", pattern, err))
+			continue
+		}
+		if len(files) == 0 {
+			problems = append(problems, fmt.Errorf("%s: no catalog files found", pattern))
+		}
+
+		for _, file := range files {
+			read, err := readFile(fsys, file)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", file, err))
+				continue
+			}
+
+			for i, e := range read {
+				where := fmt.Sprintf("%s: entry %d", file, i+1)
+				if e.ID == "" {
+					problems = append(problems, fmt.Errorf("%s: missing id", where))
+				} else {
+					where += fmt.Sprintf(" %q", e.ID)
+					if prev, ok := ids[e.ID]; ok {
+						problems = append(problems, fmt.Errorf("%s: duplicate id, already defined in %s", where, prev))
+					}
+					ids[e.ID] = file
+				}
+				if strings.TrimSpace(e.Messages[sperror.En]) == "" {
+					problems = append(problems, fmt.Errorf("%s: missing %q message", where, sperror.En))
+				}
+				if lvl, ok := levels.Parse(e.Level); !ok {
+					problems = append(problems, fmt.Errorf("%s: unknown level %q", where, e.Level))
+				} else if lvl == levels.LevelNoop {
+					problems = append(problems, fmt.Errorf("%s: level %q means no error", where, e.Level))
+				}
+			}
+			entries = append(entries, read...)
+		}
+	}
+
+	if len(problems) != 0 {
+		return nil, invalidCatalog(problems)
+	}
+	return entries, nil
+}
+
+// Load reads the catalog from files of fsys matching the glob patterns and registers every entry.
+// Entries can then be retrieved by their catalog id with Lookup.
+//
+// Nothing is registered if the catalog is invalid or any entry conflicts with an already registered error.
+//
+// Usage:
+//
+//	//go:embed errors/*.yaml
+//	var catalog embed.FS
+//
+//	err := reg.Load(catalog, "errors/*.yaml")
+func (r *Registry) Load(fsys fs.FS, patterns ...string) error {
+	entries, err := ReadCatalog(fsys, patterns...)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	var problems []error
+	batch := make(map[uint64]string, len(entries))
+	for _, e := range entries {
+		id := ID(sperror.New(e.Sample()))
+		if _, ok := r.errs[id]; ok {
+			problems = append(problems, fmt.Errorf("%q: conflicts with an already registered error", e.ID))
+		}
+		if _, ok := r.names[e.ID]; ok {
+			problems = append(problems, fmt.Errorf("%q: id is already loaded", e.ID))
+		}
+		if prev, ok := batch[id]; ok {
+			problems = append(problems, fmt.Errorf("%q: has the same code as %q", e.ID, prev))
+		}
+		batch[id] = e.ID
+	}
+	if len(problems) != 0 {
+		return invalidCatalog(problems)
+	}
+
+	for _, e := range entries {
+		err := sperror.New(e.Sample()).Freeze()
+		id := ID(err)
+		r.errs[id] = err
+		r.names[e.ID] = id
+	}
+	return nil
+}
+
+// LoadFile loads the catalog from a single file of the OS filesystem, see Load.
+func (r *Registry) LoadFile(name string) error {
+	return r.Load(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+}
+
+// Lookup returns an error by its catalog id.
+// If the error is not found, it returns nil.
+// The returned error is a fresh copy of the original error with the source set to the caller.
+func (r *Registry) Lookup(id string) error {
+	r.RLock()
+	h, ok := r.names[id]
+	e := r.errs[h]
+	r.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return e.Copy().HelperSetSource()
+}
+
+func readFile(fsys fs.FS, name string) ([]Entry, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &entries)
+	case ".json":
+		err = json.Unmarshal(data, &entries)
+	default:
+		err = fmt.Errorf("unsupported catalog format %q", path.Ext(name))
+	}
+	return entries, err
+}
+
+func invalidCatalog(problems []error) *sperror.Error {
+	list := make([]string, len(problems))
+	for i, p := range problems {
+		list[i] = p.Error()
+	}
+
+	return sperror.New(sperror.Sample{
+		Messages: map[string]string{
+			sperror.En: "Invalid error catalog",
+			sperror.Ru: "Некорректный каталог ошибок",
+		},
+		Desc:     fmt.Sprintf("Error catalog has %d problem(s)", len(problems)),
+		Hint:     "Fix every listed problem in the catalog files",
+		HttpCode: http.StatusBadRequest,
+		Level:    levels.LevelError,
+		Cause:    errors.Join(problems...),
+		Meta: map[string]any{
+			"problems": list,
+		},
+	})
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"embed"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata
+var catalog embed.FS
+
+func TestRegistry_Load(t *testing.T) {
+	r := New()
+	if err := r.Load(catalog, "testdata/*.yaml", "testdata/*.json"); err != nil {
+		t.Fatalf("Load() error = %+v", err)
+	}
+
+	tests := []struct {
+		id   string
+		lang string
+		msg  string
+		code int
+		lvl  levels.Level
+		meta map[string]any
+	}{
+		{id: "user_not_found", lang: sperror.Ru, msg: "Пользователь не найден", code: http.StatusNotFound, lvl: levels.LevelUser, meta: map[string]any{"kind": "user"}},
+		{id: "user_banned", lang: sperror.En, msg: "User is banned", code: http.StatusForbidden, lvl: levels.LevelUser, meta: map[string]any{}},
+		{id: "card_declined", lang: sperror.De, msg: "Karte abgelehnt", code: http.StatusPaymentRequired, lvl: levels.LevelInfo, meta: map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			err := r.Lookup(tt.id)
+			if err == nil {
+				t.Fatalf("Lookup() = nil")
+			}
+			e := sperror.Ensure(err)
+			if e.Msg(tt.lang) != tt.msg || e.Code() != tt.code || e.Level() != tt.lvl || !reflect.DeepEqual(e.AllMeta(), tt.meta) {
+				t.Errorf("Lookup() = %+v", e)
+			}
+			if r.Get(ID(e)) == nil {
+				t.Errorf("Get() = nil, want error loaded from catalog")
+			}
+			if !r.errs[ID(e)].Frozen() {
+				t.Errorf("Load() stored an error that is not frozen")
+			}
+			if !strings.Contains(e.Source(), "catalog_test.go") {
+				t.Errorf("Source() = %v, want caller", e.Source())
+			}
+		})
+	}
+
+	if r.Lookup("unknown") != nil {
+		t.Errorf("Lookup() of unknown id = %v, want nil", r.Lookup("unknown"))
+	}
+	if err := r.Load(catalog, "testdata/users.yaml"); err == nil {
+		t.Errorf("Load() of already loaded catalog error = nil")
+	}
+}
+
+func TestReadCatalog(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.yaml": {Data: []byte(`
+- id: dup
+  messages: {en: A}
+  level: user
+- id: no_en
+  messages: {ru: Б}
+  level: user
+- messages: {en: C}
+  level: fatal
+`)},
+		"b.json": {Data: []byte(`[{"id": "dup", "messages": {"en": "D"}, "level": "error"}, {"id": "quiet", "messages": {"en": "E"}, "level": "noop"}]`)},
+		"c.txt":  {Data: []byte(`id: x`)},
+		"d.json": {Data: []byte(`{`)},
+	}
+
+	_, err := ReadCatalog(fsys, "*.yaml", "*.json", "*.txt", "*.xml")
+	if err == nil {
+		t.Fatal("ReadCatalog() error = nil")
+	}
+
+	problems, _ := sperror.Ensure(err).Meta("problems").([]string)
+	want := []string{
+		`a.yaml: entry 2 "no_en": missing "en" message`,
+		`a.yaml: entry 3: missing id`,
+		`a.yaml: entry 3: unknown level "fatal"`,
+		`b.json: entry 1 "dup": duplicate id, already defined in a.yaml`,
+		`b.json: entry 2 "quiet": level "noop" means no error`,
+		`d.json: unexpected end of JSON input`,
+		`c.txt: unsupported catalog format ".txt"`,
+		`*.xml: no catalog files found`,
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("ReadCatalog() problems =\n%s\nwant\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+}
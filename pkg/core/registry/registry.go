@@ -1,78 +1,130 @@
 package registry
 
 import (
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"sync"
+
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
-	"hash"
-	"sync"
 )
 
-// TODO: arc and create registry system + tests
-
 type (
-	// registry stores and manages Error instances with thread-safe access.
-	// it uses a hash-based mapping to store errors and includes a mutex for concurrent operations.
-	registry struct {
-		errs map[int]*sperror.Error
+	// Registry stores and manages pre-defined Error instances with thread-safe access.
+	// It uses a hash-based mapping to store errors, so every error gets a stable ID across restarts and services.
+	// Errors loaded from a catalog can also be retrieved by their catalog id.
+	Registry struct {
+		errs  map[uint64]*sperror.Error
+		names map[string]uint64
 		sync.RWMutex
 	}
 )
 
-// Reg registers an error in the registry.
-// It returns a hash id of the error.
-// If the error is already registered, it returns the hash id of the existing error.
-// If the error is nil, it returns an error.
-// The error is validated and stored in the registry.
-func (r *registry) Reg(e error) (hash.Hash, error) {
-	r.Lock()
-	defer r.Unlock()
+// New creates an empty Registry.
+func New() *Registry {
+	return &Registry{
+		errs:  make(map[uint64]*sperror.Error),
+		names: make(map[string]uint64),
+	}
+}
 
-	if e == nil {
-		return nil, sperror.New(sperror.Sample{
+// ID returns the deterministic id of the error.
+// It is an FNV-1a hash of the error code (see sperror.Error.SetErrCode), so rewording the error keeps its id.
+// Errors without a code are hashed by the English message, the description and the HTTP code as templates, not rendered,
+// so the same error always gets the same id.
+func ID(e *sperror.Error) uint64 {
+	h := fnv.New64a()
+	if code := e.ErrCode(); code != "" {
+		h.Write([]byte(code))
+		return h.Sum64()
+	}
+	h.Write([]byte(e.User.Messages[sperror.En]))
+	h.Write([]byte{0})
+	h.Write([]byte(e.Core.Desc))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(e.Code())))
+	return h.Sum64()
+}
+
+// Reg registers an error in the registry.
+// It returns the id of the error, see ID.
+// If the error is nil or has neither a code, nor an English message, nor a description, it returns an error.
+// If an error with the same id is already registered, it returns the id and a conflict error.
+// The registry keeps its own frozen copy of the error with its whole Wrap chain, so later changes of e do not affect it.
+func (r *Registry) Reg(err error) (uint64, error) {
+	if err == nil {
+		return 0, sperror.New(sperror.Sample{
 			Messages: map[string]string{
 				sperror.En: "Nil error provided",
 				sperror.Ru: "передана nil ошибка",
 			},
 			Desc:     "Provided error is nil. This is not allowed)",
 			Hint:     "Please, check your code and provide a valid error",
-			HttpCode: 400,
+			HttpCode: http.StatusBadRequest,
 			Level:    levels.LevelError,
 		})
 	}
 
-	//h, err := e.done()
-	//if err != nil {
-	//	return nil, sperror.New(sperror.Sample{
-	//		Messages: map[string]string{
-	//			sperror.En: "Failed to validate Error",
-	//			sperror.Ru: "Ошибка в процессе валидации",
-	//		},
-	//		Desc:     "Failed to create hash id of your error. It happens when you try to register an error with an empty description. Provided data of error in Meta",
-	//		Hint:     "Please, check your fields and provide a valid description, hint and EN message for your error",
-	//		HttpCode: 400,
-	//		Level:    levels.LevelError,
-	//		Cause:    err,
-	//	})
-	//}
-	//
-	//r.errs[h] = e
-	return nil, nil
+	e := sperror.Ensure(err)
+	if e.ErrCode() == "" && e.User.Messages[sperror.En] == "" && e.Core.Desc == "" {
+		return 0, sperror.New(sperror.Sample{
+			Messages: map[string]string{
+				sperror.En: "Failed to validate Error",
+				sperror.Ru: "Ошибка в процессе валидации",
+			},
+			Desc:     "Failed to create hash id of your error. It happens when you try to register an error without EN message and description",
+			Hint:     "Please, check your fields and provide a valid description, hint and EN message for your error",
+			HttpCode: http.StatusBadRequest,
+			Level:    levels.LevelError,
+		})
+	}
+
+	id := ID(e)
+
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.errs[id]; ok {
+		return id, sperror.New(sperror.Sample{
+			Messages: map[string]string{
+				sperror.En: sperror.ErrConflict,
+				sperror.Ru: "Ошибка уже зарегистрирована",
+			},
+			Desc:     "Error with the same code, or EN message, description and HTTP code is already registered",
+			Hint:     "Please, register every error once or change its message, description or code",
+			HttpCode: http.StatusConflict,
+			Level:    levels.LevelError,
+			Meta: map[string]any{
+				"id": id,
+			},
+		})
+	}
+
+	r.errs[id] = e.Copy().Freeze()
+	return id, nil
+}
+
+// MustReg is like Reg but panics if the error cannot be registered.
+// It simplifies safe initialization of global variables holding error ids.
+func (r *Registry) MustReg(err error) uint64 {
+	id, e := r.Reg(err)
+	if e != nil {
+		panic(e)
+	}
+	return id
 }
 
-// Get returns an error by its hash id.
+// Get returns an error by its id.
 // If the error is not found, it returns nil.
-// The returned error is a copy of the original error.
-func (r *registry) Get(id int) error {
+// The returned error is a fresh copy of the original error with the source set to the caller.
+func (r *Registry) Get(id uint64) error {
 	r.RLock()
-	defer r.RUnlock()
-
 	e, ok := r.errs[id]
+	r.RUnlock()
 	if !ok {
 		return nil
 	}
 
-	cp := &sperror.Error{}
-	*cp = *sperror.Ensure(e)
-
-	return cp.HelperSetSource()
+	return e.Copy().HelperSetSource()
 }
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"errors"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sample() *sperror.Error {
+	return sperror.New(sperror.Sample{
+		Messages: map[string]string{
+			sperror.En: "User not found",
+			sperror.Ru: "Пользователь не найден",
+		},
+		Desc:     "No user with such id",
+		Hint:     "Check user id",
+		HttpCode: http.StatusNotFound,
+		Level:    levels.LevelUser,
+		Meta:     map[string]any{"kind": "user"},
+	})
+}
+
+func TestRegistry_Reg(t *testing.T) {
+	tests := []struct {
+		name     string
+		errs     []error
+		wantCode int
+	}{
+		{
+			name: "valid",
+			errs: []error{sample()},
+		},
+		{
+			name:     "nil",
+			errs:     []error{nil},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "empty",
+			errs:     []error{sperror.NewSpErr()},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "duplicate",
+			errs:     []error{sample(), sample().SetHint("another hint")},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "same code",
+			errs:     []error{sample().SetErrCode("users.not_found"), sample().SetErrCode("users.not_found").SetMsg(sperror.En, "No such user")},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "plain error",
+			errs: []error{errors.New("plain")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			var err error
+			for _, e := range tt.errs {
+				_, err = r.Reg(e)
+			}
+			if tt.wantCode == 0 && err != nil {
+				t.Fatalf("Reg() error = %v", err)
+			}
+			if tt.wantCode != 0 && (err == nil || sperror.Ensure(err).Code() != tt.wantCode) {
+				t.Fatalf("Reg() error = %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRegistry_Get(t *testing.T) {
+	r := New()
+	orig := sample()
+	id := r.MustReg(orig)
+
+	if id != ID(sample()) {
+		t.Errorf("Reg() = %v, want deterministic id %v", id, ID(sample()))
+	}
+	if r.Get(id+1) != nil {
+		t.Errorf("Get() of unknown id = %v, want nil", r.Get(id+1))
+	}
+
+	orig.AddMeta("kind", "changed")
+
+	got := sperror.Ensure(r.Get(id))
+	if !errors.Is(got, orig) || got.Msg(sperror.Ru) != orig.Msg(sperror.Ru) || got.Code() != orig.Code() {
+		t.Errorf("Get() = %v, want %v", got, orig)
+	}
+	if got.Meta("kind") != "user" {
+		t.Errorf("Get() meta = %v, registered error was changed from outside", got.Meta("kind"))
+	}
+	if !strings.Contains(got.Source(), "registry_test.go") {
+		t.Errorf("Source() = %v, want caller", got.Source())
+	}
+
+	got.AddMeta("kind", "mutated").SetMsg(sperror.En, "mutated")
+	again := sperror.Ensure(r.Get(id))
+	if again.Meta("kind") != "user" || again.Msg(sperror.En) != "User not found" {
+		t.Errorf("Get() = %v, copies share state", again)
+	}
+}
+
+func TestRegistry_GetChain(t *testing.T) {
+	r := New()
+	orig := sperror.Wrap(sperror.New(sperror.Sample{Desc: "db failed"}).SetStack(), sample())
+	id := r.MustReg(orig)
+
+	got := sperror.Ensure(r.Get(id))
+	if got.Depth() != 2 || got.Root().Desc() != "db failed" {
+		t.Errorf("Get() = %v, want the whole chain of %v", got, orig)
+	}
+	if len(got.Root().StackTrace()) == 0 {
+		t.Errorf("Get() lost the stack trace")
+	}
+	if got.Frozen() {
+		t.Errorf("Get() returned a frozen error")
+	}
+}
+
+func TestRegistry_GetRetry(t *testing.T) {
+	r := New()
+	id := r.MustReg(sperror.New(sperror.Sample{Code: "rates.limited", RetryAfter: 2 * time.Second, Temporary: true}))
+
+	got := sperror.Ensure(r.Get(id))
+	if !got.Retryable() || !got.IsTemporary() || got.RetryAfter() != 2*time.Second {
+		t.Errorf("Get() retry = %v %v %v, want the registered classification", got.Retryable(), got.IsTemporary(), got.RetryAfter())
+	}
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.Reg(sample())
+		}()
+		go func() {
+			defer wg.Done()
+			r.Get(ID(sample()))
+		}()
+	}
+	wg.Wait()
+
+	if r.Get(ID(sample())) == nil {
+		t.Errorf("Get() = nil, want registered error")
+	}
+}
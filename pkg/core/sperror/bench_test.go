@@ -0,0 +1,114 @@
+package sperror
+
+import (
+	"database/sql"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"testing"
+)
+
+var benchSample = Sample{
+	Code:     "users.not_found",
+	Desc:     "User not found",
+	Hint:     "Check user id",
+	HttpCode: 404,
+	Level:    levels.LevelUser,
+}
+
+func benchChain() *Error {
+	return WrapNew(WrapNew(Internal(sql.ErrNoRows, "query failed", "check query"), Sample{
+		Desc:  "repository failed",
+		Level: levels.LevelError,
+	}), benchSample)
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		_ = New(benchSample)
+	}
+}
+
+func BenchmarkNew_Maps(b *testing.B) {
+	s := benchSample
+	s.Messages = map[string]string{En: "Not found", Ru: "Не найдено"}
+	s.Meta = map[string]any{"user_id": 42}
+
+	b.ReportAllocs()
+	for range b.N {
+		_ = New(s)
+	}
+}
+
+func BenchmarkWrapNew(b *testing.B) {
+	src := New(benchSample)
+
+	b.ReportAllocs()
+	for range b.N {
+		_ = WrapNew(src, benchSample)
+	}
+}
+
+func BenchmarkSpin(b *testing.B) {
+	err := benchChain()
+
+	b.ReportAllocs()
+	for range b.N {
+		_ = err.Spin(levels.LevelDebug)
+	}
+}
+
+func BenchmarkEnsure(b *testing.B) {
+	b.ReportAllocs()
+	b.Run("sperror", func(b *testing.B) {
+		err := error(New(benchSample))
+		for range b.N {
+			_ = Ensure(err)
+		}
+	})
+	b.Run("foreign", func(b *testing.B) {
+		for range b.N {
+			_ = Ensure(sql.ErrNoRows)
+		}
+	})
+}
+
+// TestAllocs keeps the hot path within its allocation budget.
+func TestAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("allocation budgets are checked in full runs")
+	}
+	src := New(benchSample)
+	chain := benchChain()
+	sperr := error(src)
+
+	tests := []struct {
+		name   string
+		budget float64
+		stack  bool
+		fn     func()
+	}{
+		{name: "New", budget: 1, fn: func() { _ = New(benchSample) }},
+		{name: "WrapNew", budget: 1, fn: func() { _ = WrapNew(src, benchSample) }},
+		{name: "Spin", budget: 1, fn: func() { _ = chain.Spin(levels.LevelDebug) }},
+		{name: "Ensure", budget: 0, fn: func() { _ = Ensure(sperr) }},
+		{name: "Ensure foreign", budget: 3, fn: func() { _ = Ensure(sql.ErrNoRows) }},
+		{name: "Desc", budget: 0, fn: func() { _, _ = chain.Desc(), chain.Hint() }},
+		{name: "ContextValue", budget: 0, fn: func() { _, _ = chain.ContextValue(MetaRequestID) }},
+		// the Error, the stack and its program counters: constructors capture the stack once
+		{name: "New with stack", budget: 3, stack: true, fn: func() { _ = New(benchSample) }},
+		{name: "WrapNew with stack", budget: 3, stack: true, fn: func() { _ = WrapNew(src, benchSample) }},
+		{name: "Builder with stack", budget: 3, stack: true, fn: func() { _ = Builder() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.stack {
+				EnableStack(true)
+				defer EnableStack(false)
+			}
+			if got := testing.AllocsPerRun(100, tt.fn); got > tt.budget {
+				t.Errorf("%s allocates %v times, budget is %v", tt.name, got, tt.budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package sperror
+
+import (
+	"iter"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+)
+
+// Layers returns an iterator over the layers of the Wrap chain with their depth, starting from the outer layer (0).
+//
+// Layers are yielded as they are, without copying, so they must not be modified.
+// Use Copy to get a layer that can be changed, or FindLevel and Spin to get a detached one.
+//
+// Usage:
+//
+//	for i, layer := range err.Layers() {
+//		log.Printf("%d: %s (level %d)", i, layer.Desc(), layer.Level())
+//	}
+func (e *Error) Layers() iter.Seq2[int, *Error] {
+	return func(yield func(int, *Error) bool) {
+		for i, cur := 0, e; cur != nil; i, cur = i+1, cur.underlying {
+			if !yield(i, cur) {
+				return
+			}
+		}
+	}
+}
+
+// Causes returns an iterator over the causes of all layers of the Wrap chain, starting from the outer layer.
+// Layers without a cause are skipped. Causes joined with errors.Join are yielded as one error.
+func (e *Error) Causes() iter.Seq[error] {
+	return func(yield func(error) bool) {
+		for _, layer := range e.Layers() {
+			if layer.Core.Cause != nil && !yield(layer.Core.Cause) {
+				return
+			}
+		}
+	}
+}
+
+// Root returns the innermost layer of the Wrap chain, detached from the chain.
+// For an error that wraps nothing it is a copy of the error itself.
+func (e *Error) Root() *Error {
+	var root *Error
+	for _, layer := range e.Layers() {
+		root = layer
+	}
+	return root.detach()
+}
+
+// Depth returns the number of layers of the Wrap chain, 1 for an error that wraps nothing.
+func (e *Error) Depth() int {
+	n := 0
+	for range e.Layers() {
+		n++
+	}
+	return n
+}
+
+// FindLevel returns the outermost layer of the Wrap chain with exactly the provided level, detached from the chain.
+// It returns nil if there is no such layer.
+func (e *Error) FindLevel(lvl levels.Level) *Error {
+	for _, layer := range e.Layers() {
+		if layer.User.Level == lvl {
+			return layer.detach()
+		}
+	}
+	return nil
+}
+
+// detach returns a copy of the layer without the layers it wraps.
+func (e *Error) detach() *Error {
+	if e == nil {
+		return nil
+	}
+	layer := *e
+	layer.underlying = nil
+	layer.remainsUnderlying = 0
+	return &layer
+}
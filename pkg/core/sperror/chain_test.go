@@ -0,0 +1,122 @@
+package sperror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"reflect"
+	"testing"
+)
+
+func ExampleError_Layers() {
+	err := WrapNew(Internal(sql.ErrNoRows, "query failed", "check query"), Sample{
+		Desc:  "service failed",
+		Level: levels.LevelError,
+	})
+
+	for i, layer := range err.Layers() {
+		fmt.Println(i, layer.Desc(), layer.Level())
+	}
+	fmt.Println(err.Depth(), err.Root().Desc())
+	// Output:
+	// 0 service failed 64
+	// 1 query failed 2
+	// 2 query failed
+}
+
+func TestError_Layers(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        *Error
+		wantDescs  []string
+		wantCauses []string
+	}{
+		{
+			name:      "single layer",
+			err:       New(Sample{Desc: "only"}),
+			wantDescs: []string{"only"},
+		},
+		{
+			name:      "wrap chain",
+			err:       Api(),
+			wantDescs: []string{"Internal Error", "Database error", "Failed to connect to storage"},
+		},
+		{
+			name:       "nested chains",
+			err:        Any(Any(Internal(sql.ErrNoRows, "1", "1"), "2", "2"), "3", "3"),
+			wantDescs:  []string{"3", "2", "1"},
+			wantCauses: []string{sql.ErrNoRows.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var descs, causes []string
+			for i, layer := range tt.err.Layers() {
+				if i != len(descs) {
+					t.Fatalf("Layers() index = %d, want %d", i, len(descs))
+				}
+				descs = append(descs, layer.Desc())
+			}
+			for cause := range tt.err.Causes() {
+				causes = append(causes, cause.Error())
+			}
+
+			if !reflect.DeepEqual(descs, tt.wantDescs) {
+				t.Errorf("Layers() = %v, want %v", descs, tt.wantDescs)
+			}
+			if !reflect.DeepEqual(causes, tt.wantCauses) {
+				t.Errorf("Causes() = %v, want %v", causes, tt.wantCauses)
+			}
+			if got := tt.err.Depth(); got != len(tt.wantDescs) {
+				t.Errorf("Depth() = %v, want %v", got, len(tt.wantDescs))
+			}
+			if root := tt.err.Root(); root.Desc() != tt.wantDescs[len(tt.wantDescs)-1] || root.Depth() != 1 {
+				t.Errorf("Root() = %v with depth %d", root, root.Depth())
+			}
+		})
+	}
+}
+
+func TestError_LayersBreak(t *testing.T) {
+	n := 0
+	for range Api().Layers() {
+		n++
+		break
+	}
+	for range Internal(errors.Join(sql.ErrNoRows, sql.ErrTxDone), "a", "a").Causes() {
+		n++
+		break
+	}
+	if n != 2 {
+		t.Errorf("iterations = %d, want 2", n)
+	}
+}
+
+func TestError_FindLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		lvl  levels.Level
+		want string
+	}{
+		{name: "outer", lvl: levels.LevelInfo, want: "Internal Error"},
+		{name: "inner", lvl: levels.LevelDebug, want: "Failed to connect to storage"},
+		{name: "missing", lvl: levels.LevelUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Api().FindLevel(tt.lvl)
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("FindLevel() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Desc() != tt.want || got.Depth() != 1 {
+				t.Errorf("FindLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
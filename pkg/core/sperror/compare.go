@@ -6,10 +6,15 @@ import (
 )
 
 // Is checks if the provided error is a member of the Error chain.
+// If both errors have a code (see SetErrCode), they match by code, so rewording a message does not break the check.
+// Otherwise they match by the English message or the description.
 func (e *Error) Is(err error) bool {
 	switch v := err.(type) {
 	case *Error:
-		if strings.ToLower(e.Msg(En)) == strings.ToLower(v.Msg(En)) || errors.Is(e.Core.Cause, err) || v.Desc() == e.Desc() {
+		if e.User.Code != "" && v.User.Code != "" {
+			return e.User.Code == v.User.Code || errors.Is(e.Core.Cause, err)
+		}
+		if strings.ToLower(e.User.Messages[En]) == strings.ToLower(v.User.Messages[En]) || errors.Is(e.Core.Cause, err) || v.Core.Desc == e.Core.Desc {
 			return true
 		}
 	default:
@@ -20,16 +25,11 @@ func (e *Error) Is(err error) bool {
 
 // DeepIs traverses the entire Error chain to find a matching error. Returns true if
 // the provided error is found anywhere in the chain.
+//
+// The cause of every layer is checked with errors.Is, so causes joined with errors.Join are checked one by one.
 func (e *Error) DeepIs(err error) bool {
-	var cp = &Error{}
-	*cp = *e
-	var head *Error
-	for {
-		head = cp.pop()
-		if head == nil {
-			break
-		}
-		if errors.Is(head.Core.Cause, err) {
+	for cause := range e.Causes() {
+		if errors.Is(cause, err) {
 			return true
 		}
 	}
@@ -43,3 +43,46 @@ func TestError_Is(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestError_IsCode(t *testing.T) {
+	declined := New(Sample{
+		Code:     "billing.card_declined",
+		Messages: map[string]string{En: "Card declined"},
+		Desc:     "Payment provider declined the card",
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "reworded",
+			err:  New(Sample{Code: "billing.card_declined", Messages: map[string]string{En: "Your card was declined"}}),
+			want: true,
+		},
+		{
+			name: "same text, another code",
+			err:  New(Sample{Code: "billing.card_expired", Messages: map[string]string{En: "Card declined"}}),
+			want: false,
+		},
+		{
+			name: "no code",
+			err:  New(Sample{Messages: map[string]string{En: "card declined"}}),
+			want: true,
+		},
+		{
+			name: "wrapped",
+			err:  Any(New(Sample{Code: "billing.card_declined", Desc: "declined"}), "charge failed", "retry later"),
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, declined); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package sperror
 
 const (
-	ErrNotFound  = "Not found"
-	ErrInternal  = "Internal server error"
-	ErrBadReq    = "Bad request"
-	ErrAuth      = "Unauthorized"
-	ErrForbidden = "Forbidden"
-	ErrConflict  = "Conflict"
-	ErrTooMany   = "Too many requests"
+	ErrNotFound   = "Not found"
+	ErrInternal   = "Internal server error"
+	ErrBadReq     = "Bad request"
+	ErrAuth       = "Unauthorized"
+	ErrForbidden  = "Forbidden"
+	ErrConflict   = "Conflict"
+	ErrTooMany    = "Too many requests"
+	ErrUnknown    = "Unknown error"
+	ErrValidation = "Validation failed"
 )
@@ -0,0 +1,211 @@
+package sperror
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Meta keys filled by the built-in context extractors.
+const (
+	MetaRequestID = "request_id"
+	MetaUserID    = "user_id"
+	MetaTenant    = "tenant"
+	MetaTraceID   = "trace_id"
+	MetaSpanID    = "span_id"
+)
+
+type (
+	// Extractor pulls a single value from a context, e.g. a request ID set by a middleware.
+	// It returns false if the context holds no such value.
+	Extractor func(ctx context.Context) (any, bool)
+
+	ctxKey int
+)
+
+const (
+	requestIDKey ctxKey = iota
+	userIDKey
+	tenantKey
+	traceparentKey
+)
+
+var extractors = struct {
+	sync.RWMutex
+	keys []string
+	fns  map[string]Extractor
+}{
+	keys: []string{MetaRequestID, MetaUserID, MetaTenant, MetaTraceID, MetaSpanID},
+	fns: map[string]Extractor{
+		MetaRequestID: value(requestIDKey),
+		MetaUserID:    value(userIDKey),
+		MetaTenant:    value(tenantKey),
+		MetaTraceID:   traceparent(1),
+		MetaSpanID:    traceparent(2),
+	},
+}
+
+// RegisterExtractor registers fn to fill the meta key of errors created with NewCtx or WithContext.
+//
+// Registering a key again replaces its extractor, e.g. to take the trace id from an OpenTelemetry span:
+//
+//	sperror.RegisterExtractor(sperror.MetaTraceID, func(ctx context.Context) (any, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.HasTraceID()
+//	})
+//
+// Passing a nil fn removes the extractor of the key.
+func RegisterExtractor(key string, fn Extractor) {
+	extractors.Lock()
+	defer extractors.Unlock()
+
+	// keys and fns are never modified in place, so readers may keep them after unlocking
+	fns := maps.Clone(extractors.fns)
+	if fn == nil {
+		delete(fns, key)
+		extractors.keys = slices.DeleteFunc(slices.Clone(extractors.keys), func(k string) bool { return k == key })
+		extractors.fns = fns
+		return
+	}
+	if _, ok := fns[key]; !ok {
+		extractors.keys = append(slices.Clip(extractors.keys), key)
+	}
+	fns[key] = fn
+	extractors.fns = fns
+}
+
+// ContextKeys returns the meta keys of the registered extractors in registration order.
+func ContextKeys() []string {
+	extractors.RLock()
+	defer extractors.RUnlock()
+	return slices.Clone(extractors.keys)
+}
+
+// contextKeys returns the meta keys of the registered extractors without copying them.
+func contextKeys() []string {
+	extractors.RLock()
+	defer extractors.RUnlock()
+	return extractors.keys
+}
+
+// NewCtx is like New, but also fills meta with values of the registered extractors, see WithContext.
+func NewCtx(ctx context.Context, s Sample) *Error {
+	return newAt(s, 1).WithContext(ctx)
+}
+
+// WithContext fills meta with values pulled from ctx by the registered extractors:
+// request id, user id, tenant, trace and span ids of the W3C traceparent, and any registered with RegisterExtractor.
+// Values already present in meta are not overwritten.
+//
+// WithContext modifies e, so call it on fresh errors, not on predefined ones shared between requests,
+// unless they are frozen, see Freeze.
+func (e *Error) WithContext(ctx context.Context) *Error {
+	if ctx == nil {
+		return e
+	}
+	e = e.writable()
+
+	// extractors are called without the lock, so they may register extractors themselves
+	extractors.RLock()
+	keys, fns := extractors.keys, extractors.fns
+	extractors.RUnlock()
+
+	for _, key := range keys {
+		if _, ok := e.meta[key]; ok {
+			continue
+		}
+		if v, ok := fns[key](ctx); ok {
+			if e.meta == nil {
+				e.meta = make(map[string]any)
+			}
+			e.meta[key] = v
+		}
+	}
+	return e
+}
+
+// ContextMeta returns the meta values of ContextKeys found in any layer of the Wrap chain,
+// the outermost layer wins. Loggers and notifiers use it, so correlation ids set on an outer layer
+// are shown even if the error is spun to an inner one.
+func (e *Error) ContextMeta() map[string]any {
+	res := make(map[string]any)
+	for _, key := range contextKeys() {
+		if v, ok := e.ContextValue(key); ok {
+			res[key] = v
+		}
+	}
+	return res
+}
+
+// ContextValue returns the meta value of key from the outermost layer of the Wrap chain that has it.
+// Unlike ContextMeta, it does not allocate.
+func (e *Error) ContextValue(key string) (any, bool) {
+	for _, layer := range e.Layers() {
+		if v, ok := layer.meta[key]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// WithRequestID returns a copy of ctx carrying the request id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// WithUserID returns a copy of ctx carrying the id of the authenticated user.
+func WithUserID(ctx context.Context, id any) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// WithTenant returns a copy of ctx carrying the tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// WithTraceparent returns a copy of ctx carrying the W3C traceparent header,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// Malformed headers are ignored by the extractors.
+func WithTraceparent(ctx context.Context, header string) context.Context {
+	return context.WithValue(ctx, traceparentKey, header)
+}
+
+func value(key ctxKey) Extractor {
+	return func(ctx context.Context) (any, bool) {
+		v := ctx.Value(key)
+		if s, ok := v.(string); ok && s == "" {
+			return nil, false
+		}
+		return v, v != nil
+	}
+}
+
+// traceparent returns an extractor of the part-th part of the traceparent header: 1 is the trace id, 2 is the span id.
+func traceparent(part int) Extractor {
+	return func(ctx context.Context) (any, bool) {
+		header, _ := ctx.Value(traceparentKey).(string)
+		parts := strings.Split(strings.TrimSpace(header), "-")
+		if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || parts[0] == "ff" {
+			return nil, false
+		}
+		if !isHex(parts[0]) || !isHex(parts[1]) || !isHex(parts[2]) {
+			return nil, false
+		}
+		// all-zero trace and span ids are invalid
+		if strings.Trim(parts[part], "0") == "" {
+			return nil, false
+		}
+		return parts[part], true
+	}
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
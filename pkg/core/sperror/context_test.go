@@ -0,0 +1,134 @@
+package sperror
+
+import (
+	"context"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleNewCtx() {
+	ctx := WithRequestID(context.Background(), "req-42")
+	ctx = WithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	err := NewCtx(ctx, Sample{Desc: "payment failed", Level: levels.LevelError})
+	fmt.Println(err.Meta(MetaRequestID), err.Meta(MetaTraceID), err.Meta(MetaSpanID))
+	// Output: req-42 4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7
+}
+
+func TestError_WithContext(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		meta map[string]any
+		want map[string]any
+	}{
+		{
+			name: "empty context",
+			ctx:  context.Background(),
+			want: map[string]any{},
+		},
+		{
+			name: "all ids",
+			ctx:  WithTenant(WithUserID(WithRequestID(WithTraceparent(context.Background(), traceparent), "req-1"), 7), "acme"),
+			want: map[string]any{
+				MetaRequestID: "req-1",
+				MetaUserID:    7,
+				MetaTenant:    "acme",
+				MetaTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+				MetaSpanID:    "00f067aa0ba902b7",
+			},
+		},
+		{
+			name: "meta wins",
+			ctx:  WithRequestID(context.Background(), "req-1"),
+			meta: map[string]any{MetaRequestID: "explicit"},
+			want: map[string]any{MetaRequestID: "explicit"},
+		},
+		{
+			name: "empty request id",
+			ctx:  WithRequestID(context.Background(), ""),
+			want: map[string]any{},
+		},
+		{
+			name: "malformed traceparent",
+			ctx:  WithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"),
+			want: map[string]any{},
+		},
+		{
+			name: "zero span id",
+			ctx:  WithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"),
+			want: map[string]any{MetaTraceID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		},
+		{
+			name: "uppercase traceparent",
+			ctx:  WithTraceparent(context.Background(), "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01"),
+			want: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(Sample{Meta: tt.meta}).WithContext(tt.ctx)
+			if !reflect.DeepEqual(got.AllMeta(), tt.want) {
+				t.Errorf("WithContext() meta = %v, want %v", got.AllMeta(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterExtractor(t *testing.T) {
+	type regionKey struct{}
+
+	RegisterExtractor("region", func(ctx context.Context) (any, bool) {
+		v, ok := ctx.Value(regionKey{}).(string)
+		return v, ok
+	})
+	defer RegisterExtractor("region", nil)
+
+	ctx := WithRequestID(context.WithValue(context.Background(), regionKey{}, "eu"), "req-1")
+	err := WrapNew(NewCtx(ctx, Sample{Desc: "inner"}), Sample{Desc: "outer", Meta: map[string]any{MetaRequestID: "req-outer"}})
+
+	if keys := ContextKeys(); keys[len(keys)-1] != "region" {
+		t.Errorf("ContextKeys() = %v, want region last", keys)
+	}
+	want := map[string]any{MetaRequestID: "req-outer", "region": "eu"}
+	if got := err.ContextMeta(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ContextMeta() = %v, want %v", got, want)
+	}
+	if got := err.Spin(levels.LevelDebug).ContextMeta(); got["region"] != "eu" {
+		t.Errorf("Spin().ContextMeta() = %v, want region of the inner layer", got)
+	}
+	if src := err.Root().Source(); !strings.Contains(src, "context_test.go") {
+		t.Errorf("NewCtx() source = %v, want the caller", src)
+	}
+}
+
+func TestRegisterExtractor_Reentrant(t *testing.T) {
+	RegisterExtractor("lazy", func(ctx context.Context) (any, bool) {
+		RegisterExtractor("lazy", func(context.Context) (any, bool) { return "second", true })
+		return "first", true
+	})
+	defer RegisterExtractor("lazy", nil)
+
+	done := make(chan *Error)
+	go func() {
+		done <- NewCtx(context.Background(), Sample{})
+	}()
+	select {
+	case err := <-done:
+		if err.Meta("lazy") != "first" {
+			t.Errorf("Meta() = %v, want first", err.Meta("lazy"))
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("WithContext() deadlocked on an extractor registering another one")
+	}
+	if got := NewCtx(context.Background(), Sample{}).Meta("lazy"); got != "second" {
+		t.Errorf("Meta() = %v, want second", got)
+	}
+}
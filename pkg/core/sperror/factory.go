@@ -3,11 +3,8 @@
 package sperror
 
 import (
-	"fmt"
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	"maps"
-	"path/filepath"
-	"runtime"
 )
 
 type (
@@ -22,6 +19,7 @@ type (
 		Messages map[string]string // localized message
 		HttpCode int               // HTTP status
 		Level    levels.Level      // error level
+		Code     string            // stable machine-readable code, independent of the message text
 	}
 
 	// Error represents a structured error type with extended information and metadata.
@@ -30,7 +28,13 @@ type (
 		Core CoreError
 		User UserError
 
-		meta map[string]any // arbitrary fields (user_id, trace_id, etc.)
+		meta  map[string]any // arbitrary fields (user_id, trace_id, etc.)
+		stack *stack         // captured call stack, resolved lazily
+
+		children []FieldError // field errors of an error built by Group
+		frozen   bool         // setters modify a copy, see Freeze
+		plain    bool         // texts are plain text rather than templates, see Sample.Plain
+		retry    retryInfo    // retry classification, see Retryable
 
 		remainsUnderlying int
 		underlying        *Error
@@ -40,48 +44,78 @@ type (
 // NewSpErr creates and returns a new instance of Error.
 // It initializes an empty Error struct that can be further configured using method chaining.
 // This is the base constructor for creating new structured errors.
+// Messages and meta maps are allocated on first use.
 func NewSpErr() *Error {
-	return &Error{
-		User: UserError{
-			Messages: make(map[string]string),
-		},
-		meta: make(map[string]any),
-	}
+	return &Error{}
 }
 
 // New constructs and returns a new Error based on the provided Sample.
 // It initializes a new Error instance, then copies all fields from the provided Sample and returns
 // the configured and ready to use Error instance
+//
+// New allocates only the Error itself and copies of non-empty messages and meta,
+// so it is cheap enough to be called on hot paths.
 func New(s Sample) *Error {
-	sp := NewSpErr()
+	return newAt(s, 1)
+}
 
-	if sp.User.Messages == nil {
-		sp.User.Messages = make(map[string]string)
-	}
-	maps.Copy(sp.User.Messages, s.Messages)
+// HelperNew is New for constructors that wrap it: the source is set to the caller of the function
+// that calls HelperNew, as with HelperSetSource, but the caller's stack is captured only once.
+func HelperNew(s Sample) *Error {
+	return newAt(s, 2)
+}
 
-	if sp.meta == nil {
-		sp.meta = make(map[string]any)
-	}
-	maps.Copy(sp.meta, s.Meta)
+// newAt is New that sets the source to the caller lvl frames above the caller of newAt, see path.
+// Constructors call it instead of New followed by path, so the stack is captured only once.
+func newAt(s Sample, lvl int) *Error {
+	return build(s).path(lvl + 1)
+}
 
-	return sp.
-		SetDesc(s.Desc).
-		SetHint(s.Hint).
-		SetCode(s.HttpCode).
-		SetLevel(s.Level).
-		SetCaused(s.Cause).
-		path(1)
+// build returns the layer of s without a source and a stack.
+func build(s Sample) *Error {
+	sp := &Error{
+		Core: CoreError{
+			Desc:  s.Desc,
+			Hint:  s.Hint,
+			Cause: s.Cause,
+		},
+		User: UserError{
+			HttpCode: s.HttpCode,
+			Level:    s.Level,
+			Code:     s.Code,
+		},
+		plain: s.Plain,
+	}
+	if len(s.Messages) != 0 {
+		sp.User.Messages = maps.Clone(s.Messages)
+	}
+	if len(s.Meta) != 0 {
+		sp.meta = maps.Clone(s.Meta)
+	}
+	if s.Retryable || s.Temporary || s.Timeout || s.RetryAfter != 0 {
+		sp.retry = retryInfo{
+			set:       true,
+			retryable: s.Retryable || s.RetryAfter > 0,
+			temporary: s.Temporary,
+			timeout:   s.Timeout,
+			after:     s.RetryAfter,
+		}
+	}
+	return sp
 }
 
 // SetCaused sets the underlying error.
 func (e *Error) SetCaused(err error) *Error {
+	e = e.writable()
 	e.Core.Cause = err
 	return e
 }
 
 // SetMsg sets the localized message for the given language.
+// On an error of plain texts (see Sample.Plain) the other texts are escaped, so msg is a template like them.
 func (e *Error) SetMsg(lg, msg string) *Error {
+	e = e.writable()
+	e.templates()
 	if e.User.Messages == nil {
 		e.User.Messages = make(map[string]string)
 	}
@@ -91,13 +125,19 @@ func (e *Error) SetMsg(lg, msg string) *Error {
 }
 
 // SetDesc sets the complete description for the given language.
+// On an error of plain texts (see Sample.Plain) the other texts are escaped, so desc is a template like them.
 func (e *Error) SetDesc(desc string) *Error {
+	e = e.writable()
+	e.templates()
 	e.Core.Desc = desc
 	return e
 }
 
 // SetHint sets the hint for the given language.
+// On an error of plain texts (see Sample.Plain) the other texts are escaped, so hint is a template like them.
 func (e *Error) SetHint(hint string) *Error {
+	e = e.writable()
+	e.templates()
 	e.Core.Hint = hint
 	return e
 }
@@ -105,13 +145,23 @@ func (e *Error) SetHint(hint string) *Error {
 // SetCode sets the HTTP status code for the error.
 // It accepts an integer representing the HTTP status code and returns the modified Error.
 func (e *Error) SetCode(httpCode int) *Error {
+	e = e.writable()
 	e.User.HttpCode = httpCode
 	return e
 }
 
+// SetErrCode sets the stable machine-readable code of the error, e.g. "billing.card_declined".
+// Unlike messages, the code is not translated, so clients and dashboards can key on it.
+func (e *Error) SetErrCode(code string) *Error {
+	e = e.writable()
+	e.User.Code = code
+	return e
+}
+
 // SetLevel sets the severity level of the error.
 // It accepts a Level value and returns the modified Error.
 func (e *Error) SetLevel(lvl levels.Level) *Error {
+	e = e.writable()
 	e.User.Level = lvl
 	return e
 }
@@ -119,6 +169,10 @@ func (e *Error) SetLevel(lvl levels.Level) *Error {
 // AddMeta adds a key-value pair to the error's metadata.
 // It accepts a string key and any value, returning the modified Error.
 func (e *Error) AddMeta(key string, val any) *Error {
+	e = e.writable()
+	if e.meta == nil {
+		e.meta = make(map[string]any)
+	}
 	e.meta[key] = val
 	return e
 }
@@ -127,24 +181,21 @@ func (e *Error) AddMeta(key string, val any) *Error {
 // Stack frame level to look up (relative to caller)
 // Returns:
 // - *Error: The modified error instance with source set
-// The source format is "absolute_file_path:line_number"
+// The source format is "file_path:line_number", the path is absolute unless the binary is built with -trimpath
+// The caller's stack trace is captured as well if it is enabled for the error's level
 func (e *Error) path(lvl int) *Error {
-	_, file, line, ok := runtime.Caller(lvl + 1)
-	if ok {
-		absPath, err := filepath.Abs(file)
-		if err != nil {
-			panic(err)
-		}
-		e.Core.Source = fmt.Sprintf("%s:%d", absPath, line)
+	e = e.writable()
+	if src, ok := source(lvl + 1); ok {
+		e.Core.Source = src
 	}
-	return e
+	return e.captureStack(lvl + 1)
 }
 
 // SetSource sets the error source based on the caller's location
 // Stack frame level to look up (relative to caller)
 // Returns:
 // - *Error: The modified error instance with source set
-// The source format is "absolute_file_path:line_number"
+// The source format is "file_path:line_number"
 func (e *Error) SetSource() *Error {
 	return e.path(1)
 }
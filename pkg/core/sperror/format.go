@@ -0,0 +1,76 @@
+package sperror
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Format implements fmt.Formatter.
+//
+// Supported verbs:
+//   - %s, %v  → Error() of the outer layer, as before
+//   - %q      → quoted Error() of the outer layer
+//   - %+v     → every layer of the Wrap chain with its level, code, error code, source, meta and cause, one layer per line
+//   - %#v     → Go-syntax representation of the whole chain
+func (e *Error) Format(s fmt.State, verb rune) {
+	if e == nil {
+		if verb == 'v' && s.Flag('#') {
+			io.WriteString(s, "(*sperror.Error)(nil)")
+			return
+		}
+		io.WriteString(s, "<nil>")
+		return
+	}
+
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			e.formatChain(s)
+		case s.Flag('#'):
+			e.formatGo(s)
+		default:
+			io.WriteString(s, e.Error())
+		}
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		io.WriteString(s, strconv.Quote(e.Error()))
+	default:
+		fmt.Fprintf(s, "%%!%c(*sperror.Error=%s)", verb, e.Error())
+	}
+}
+
+// formatChain writes one line per layer, starting from the outer one.
+func (e *Error) formatChain(w io.Writer) {
+	for i, cur := range e.Layers() {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		fmt.Fprintf(w, "[%d] %s | level=%d code=%d", i, cur.Error(), cur.User.Level, cur.User.HttpCode)
+		if cur.User.Code != "" {
+			fmt.Fprintf(w, " errcode=%s", cur.User.Code)
+		}
+		if cur.Core.Source != "" {
+			fmt.Fprintf(w, " source=%s", cur.Core.Source)
+		}
+		if len(cur.meta) != 0 {
+			fmt.Fprintf(w, " meta=%v", cur.meta)
+		}
+		if cur.Core.Cause != nil {
+			fmt.Fprintf(w, " cause=%q", cur.Core.Cause.Error())
+		}
+	}
+}
+
+// formatGo writes the Go-syntax representation of the error.
+// The stack trace is omitted, since it holds raw program counters.
+func (e *Error) formatGo(w io.Writer) {
+	fmt.Fprintf(w,
+		"&sperror.Error{Core:sperror.CoreError{Desc:%q, Hint:%q, Source:%q, Cause:%#v}, "+
+			"User:sperror.UserError{Messages:%#v, HttpCode:%d, Level:%d, Code:%q}, meta:%#v, underlying:%#v}",
+		e.Core.Desc, e.Core.Hint, e.Core.Source, e.Core.Cause,
+		e.User.Messages, e.User.HttpCode, e.User.Level, e.User.Code, e.meta, e.underlying,
+	)
+}
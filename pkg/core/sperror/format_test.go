@@ -0,0 +1,109 @@
+package sperror
+
+import (
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"testing"
+)
+
+func ExampleError_Format() {
+	db := New(Sample{
+		Desc:  "Query execution failed",
+		Hint:  "Inspect DB connection",
+		Level: levels.LevelDebug,
+		Cause: errors.New("db closed"),
+	})
+	app := WrapNew(db, Sample{
+		Desc:     "Could not retrieve user profile",
+		Hint:     "Try again later",
+		HttpCode: 500,
+		Level:    levels.LevelUser,
+		Meta:     map[string]any{"user_id": 42},
+	})
+	db.Core.Source, app.Core.Source = "repo.go:10", "api.go:20"
+
+	fmt.Printf("%v\n", app)
+	fmt.Printf("%+v\n", app)
+	// Output:
+	// Could not retrieve user profile: Try again later
+	// [0] Could not retrieve user profile: Try again later | level=2 code=500 source=api.go:20 meta=map[user_id:42]
+	// [1] Query execution failed: Inspect DB connection | level=255 code=0 source=repo.go:10 cause="db closed"
+}
+
+func TestError_Format(t *testing.T) {
+	err := New(Sample{
+		Messages: map[string]string{En: "msg"},
+		Desc:     "desc",
+		Hint:     "hint",
+		HttpCode: 404,
+		Level:    levels.LevelUser,
+	})
+	err.Core.Source = "file.go:1"
+
+	var nilErr *Error
+
+	tests := []struct {
+		name   string
+		format string
+		err    error
+		want   string
+	}{
+		{
+			name:   "v",
+			format: "%v",
+			err:    err,
+			want:   "desc: hint",
+		},
+		{
+			name:   "s",
+			format: "%s",
+			err:    err,
+			want:   "desc: hint",
+		},
+		{
+			name:   "q",
+			format: "%q",
+			err:    err,
+			want:   `"desc: hint"`,
+		},
+		{
+			name:   "w",
+			format: "%v",
+			err:    fmt.Errorf("wrapped: %w", err),
+			want:   "wrapped: desc: hint",
+		},
+		{
+			name:   "plus v",
+			format: "%+v",
+			err:    err,
+			want:   "[0] desc: hint | level=2 code=404 source=file.go:1",
+		},
+		{
+			name:   "sharp v",
+			format: "%#v",
+			err:    err,
+			want:   `&sperror.Error{Core:sperror.CoreError{Desc:"desc", Hint:"hint", Source:"file.go:1", Cause:<nil>}, User:sperror.UserError{Messages:map[string]string{"en":"msg"}, HttpCode:404, Level:2, Code:""}, meta:map[string]interface {}(nil), underlying:(*sperror.Error)(nil)}`,
+		},
+		{
+			name:   "nil",
+			format: "%v",
+			err:    nilErr,
+			want:   "<nil>",
+		},
+		{
+			name:   "unknown verb",
+			format: "%d",
+			err:    err,
+			want:   "%!d(*sperror.Error=desc: hint)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprintf(tt.format, tt.err); got != tt.want {
+				t.Errorf("Sprintf(%q) = %v, want %v", tt.format, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package sperror
+
+import (
+	"errors"
+	"maps"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+)
+
+var panicOnFrozen atomic.Bool
+
+// PanicOnFrozen makes setters of frozen errors panic instead of modifying a copy.
+// It is off by default; turn it on in tests to find code that modifies shared errors.
+func PanicOnFrozen(on bool) {
+	panicOnFrozen.Store(on)
+}
+
+// Freeze marks the whole chain as immutable, including *Error causes and field errors, and returns e.
+//
+// Setters of a frozen error (SetMsg, AddMeta, WithContext, Wrap etc.) never modify it. They modify and return
+// a deep copy instead, or panic if PanicOnFrozen is on. So freeze package-level errors shared between goroutines
+// and always use the returned error:
+//
+//	var ErrNotFound = sperror.NotFound("User not found", "Check user id").Freeze()
+//
+//	return ErrNotFound.AddMeta("user_id", id) // a copy with the meta, ErrNotFound is unchanged
+//
+// Freeze modifies e, so call it before the error is shared.
+func (e *Error) Freeze() *Error {
+	for _, layer := range e.Layers() {
+		layer.frozen = true
+		if cause, ok := layer.Core.Cause.(*Error); ok {
+			cause.Freeze()
+		}
+		for _, c := range layer.children {
+			c.Err.Freeze()
+		}
+	}
+	return e
+}
+
+// Frozen reports whether e is frozen, see Freeze.
+func (e *Error) Frozen() bool {
+	return e.frozen
+}
+
+// writable returns e itself or, if e is frozen, its deep copy to be modified by a setter.
+func (e *Error) writable() *Error {
+	if !e.frozen {
+		return e
+	}
+	if panicOnFrozen.Load() {
+		panic(newAt(Sample{
+			Messages: localized(ErrUnknown),
+			Desc:     "Frozen error " + strconv.Quote(e.Core.Desc) + " is modified",
+			Hint:     "Copy the error before modifying it or use the error returned by the setter",
+			Plain:    true,
+			Level:    levels.LevelError,
+		}, 2))
+	}
+	return e.Copy()
+}
+
+// copyMeta returns a copy of meta, nested maps and slices are copied as well.
+func copyMeta(meta map[string]any) map[string]any {
+	if meta == nil {
+		return nil
+	}
+	res := make(map[string]any, len(meta))
+	for k, v := range meta {
+		res[k] = copyValue(v)
+	}
+	return res
+}
+
+func copyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return copyMeta(v)
+	case map[string]string:
+		return maps.Clone(v)
+	case []any:
+		res := make([]any, len(v))
+		for i, val := range v {
+			res[i] = copyValue(val)
+		}
+		return res
+	case []string:
+		return append([]string(nil), v...)
+	}
+	return v
+}
+
+// copyChildren returns copies of the field errors and their join to be used as the cause.
+func copyChildren(children []FieldError) ([]FieldError, error) {
+	res := make([]FieldError, len(children))
+	errs := make([]error, len(children))
+	for i, c := range children {
+		res[i] = FieldError{Field: c.Field, Err: c.Err.Copy()}
+		errs[i] = res[i].Err
+	}
+	return res, errors.Join(errs...)
+}
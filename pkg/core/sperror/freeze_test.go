@@ -0,0 +1,153 @@
+package sperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func ExampleError_Freeze() {
+	ErrNotFound := NotFound("User not found", "Check user id").Freeze()
+
+	err := ErrNotFound.AddMeta("user_id", 42)
+	fmt.Println(err.Meta("user_id"), ErrNotFound.Meta("user_id"), err.Frozen())
+	// Output: 42 <nil> false
+}
+
+func TestError_Copy(t *testing.T) {
+	inner := New(Sample{Desc: "inner", Level: levels.LevelDebug, Meta: map[string]any{"tags": []any{"a"}}})
+	orig := WrapNew(inner, Sample{
+		Messages: map[string]string{En: "outer"},
+		Desc:     "outer",
+		Level:    levels.LevelError,
+		Cause:    New(Sample{Desc: "cause"}),
+		Meta:     map[string]any{"nested": map[string]any{"k": "v"}},
+	})
+	group := new(Group).Add("name", New(Sample{Desc: "name"})).Err()
+
+	cp := orig.Copy()
+	cp.SetMsg(En, "changed").AddMeta("new", 1)
+	cp.meta["nested"].(map[string]any)["k"] = "changed"
+	cp.underlying.AddMeta("new", 1)
+	cp.underlying.meta["tags"].([]any)[0] = "changed"
+	cp.Core.Cause.(*Error).AddMeta("new", 1)
+
+	if !reflect.DeepEqual(orig.AllMeta(), map[string]any{"nested": map[string]any{"k": "v"}}) || orig.Msg(En) != "outer" {
+		t.Errorf("Copy() shares the outer layer: %v %v", orig.AllMeta(), orig.Msg(En))
+	}
+	if !reflect.DeepEqual(inner.AllMeta(), map[string]any{"tags": []any{"a"}}) {
+		t.Errorf("Copy() shares the underlying layer: %v", inner.AllMeta())
+	}
+	if orig.Caused().(*Error).Meta("new") != nil {
+		t.Errorf("Copy() shares the cause")
+	}
+	if cp.Depth() != orig.Depth() || !errors.Is(cp, inner) {
+		t.Errorf("Copy() chain = %v, want %v", cp, orig)
+	}
+
+	gc := group.Copy()
+	gc.Children()[0].Err.AddMeta("new", 1)
+	if group.Children()[0].Err.Meta("new") != nil {
+		t.Errorf("Copy() shares field errors")
+	}
+	if !errors.Is(gc.Caused(), gc.Children()[0].Err) {
+		t.Errorf("Copy() cause does not join the copied field errors")
+	}
+}
+
+func TestError_Freeze(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(e *Error) *Error
+	}{
+		{name: "SetMsg", set: func(e *Error) *Error { return e.SetMsg(En, "changed") }},
+		{name: "SetDesc", set: func(e *Error) *Error { return e.SetDesc("changed") }},
+		{name: "SetHint", set: func(e *Error) *Error { return e.SetHint("changed") }},
+		{name: "SetCode", set: func(e *Error) *Error { return e.SetCode(418) }},
+		{name: "SetErrCode", set: func(e *Error) *Error { return e.SetErrCode("changed") }},
+		{name: "SetLevel", set: func(e *Error) *Error { return e.SetLevel(levels.LevelDebug) }},
+		{name: "SetCaused", set: func(e *Error) *Error { return e.SetCaused(errors.New("changed")) }},
+		{name: "AddMeta", set: func(e *Error) *Error { return e.AddMeta("k", "changed") }},
+		{name: "SetSource", set: func(e *Error) *Error { return e.SetSource() }},
+		{name: "SetStack", set: func(e *Error) *Error { return e.SetStack() }},
+		{name: "Wrap", set: func(e *Error) *Error { return e.Wrap(errors.New("changed")) }},
+		{name: "Wrap func", set: func(e *Error) *Error { return Wrap(New(Sample{}), e) }},
+		{name: "WithContext", set: func(e *Error) *Error { return e.WithContext(WithRequestID(context.Background(), "r")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared := NotFound("shared", "hint").Freeze()
+			before := fmt.Sprintf("%+v", shared)
+
+			got := tt.set(shared)
+			if got == shared || got.Frozen() {
+				t.Errorf("%s() returned the frozen error", tt.name)
+			}
+			if after := fmt.Sprintf("%+v", shared); after != before || !shared.Frozen() {
+				t.Errorf("%s() modified the frozen error: %v, want %v", tt.name, after, before)
+			}
+
+			PanicOnFrozen(true)
+			defer PanicOnFrozen(false)
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s() did not panic", tt.name)
+				}
+			}()
+			tt.set(shared)
+		})
+	}
+}
+
+func TestError_FreezeChain(t *testing.T) {
+	cause := New(Sample{Desc: "cause"})
+	inner := Internal(cause, "inner", "hint")
+	err := Wrap(inner, New(Sample{Desc: "outer", Level: levels.LevelError})).Freeze()
+
+	if !inner.Frozen() || !cause.Frozen() {
+		t.Fatalf("Freeze() did not freeze the chain")
+	}
+	spun := err.Spin(levels.LevelDebug)
+	if !spun.Frozen() {
+		t.Errorf("Spin() of a frozen error is not frozen")
+	}
+	if spun.AddMeta("k", "v"); inner.Meta("k") != nil {
+		t.Errorf("AddMeta() on a spun layer modified the chain")
+	}
+}
+
+// TestError_FreezeConcurrent is meant to be run with -race.
+func TestError_FreezeConcurrent(t *testing.T) {
+	shared := WrapNew(Internal(errors.New("db closed"), "query failed", "check db"), Sample{
+		Messages: map[string]string{En: "Service failed"},
+		Desc:     "service failed",
+		Level:    levels.LevelError,
+		Meta:     map[string]any{"service": "users"},
+	}).Freeze()
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := shared.AddMeta("n", i).SetMsg(Ru, "Сбой").WithContext(WithRequestID(context.Background(), "r"))
+			if err.Meta("n") != i {
+				t.Errorf("AddMeta() = %v, want %v", err.Meta("n"), i)
+			}
+			spun := shared.Spin(levels.LevelDebug).AddMeta("n", i)
+			_ = spun.Error() + fmt.Sprintf("%+v", shared) + shared.MsgFor(Ru)
+			_, _ = shared.MarshalJSON()
+			_ = shared.Copy().SetDesc("copy")
+		}()
+	}
+	wg.Wait()
+
+	if shared.Meta("n") != nil || shared.Msg(Ru) != "" {
+		t.Errorf("shared error was modified: %v", shared.AllMeta())
+	}
+}
@@ -0,0 +1,120 @@
+package sperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+)
+
+type (
+	// FieldError is a child error of a combined error built by Group.
+	FieldError struct {
+		Field string // path of the invalid field, e.g. "user.emails[0]"
+		Err   *Error
+	}
+
+	// Group collects errors of several fields, e.g. request validation failures, into one *Error.
+	//
+	// Usage:
+	//
+	//	var g sperror.Group
+	//	if req.Email == "" {
+	//		g.Add("email", sperror.BadRequest("email is empty", "Provide an email"))
+	//	}
+	//	if req.Age < 18 {
+	//		g.Add("age", ErrTooYoung)
+	//	}
+	//	if err := g.Err(); err != nil {
+	//		return err
+	//	}
+	Group struct {
+		children []FieldError
+	}
+)
+
+// Add adds the error of a field to the group. Nil errors are ignored.
+func (g *Group) Add(field string, err error) *Group {
+	if err != nil {
+		g.children = append(g.children, FieldError{Field: field, Err: Ensure(err)})
+	}
+	return g
+}
+
+// Len returns the number of errors in the group.
+func (g *Group) Len() int {
+	return len(g.children)
+}
+
+// Err combines the errors of the group into one *Error, or returns nil if the group is empty.
+//
+// The combined error gets the highest level of its children and an aggregate HTTP code:
+// the common code if all children share it, 500 if any child is a server error, 400 otherwise.
+// Its message is translated to the languages of the children where a built-in translation exists.
+// Every child keeps its own messages and is available with Children,
+// errors.Is and errors.As see the children as the cause.
+func (g *Group) Err() *Error {
+	if len(g.children) == 0 {
+		return nil
+	}
+
+	children := make([]FieldError, len(g.children))
+	copy(children, g.children)
+
+	var (
+		lvl   levels.Level
+		code  = children[0].Err.User.HttpCode
+		msgs  = localized(ErrValidation)
+		descs = make([]string, len(children))
+		errs  = make([]error, len(children))
+	)
+	for i, c := range children {
+		for lg := range c.Err.User.Messages {
+			if tr, ok := builtin[ErrValidation][lg]; ok {
+				msgs[lg] = tr
+			}
+		}
+		lvl = max(lvl, c.Err.User.Level)
+		code = aggregateCode(code, c.Err.User.HttpCode)
+		descs[i] = c.Field + ": " + c.Err.Desc()
+		errs[i] = c.Err
+	}
+	if code == 0 {
+		code = http.StatusBadRequest
+	}
+
+	e := newAt(Sample{
+		Messages: msgs,
+		Desc:     fmt.Sprintf("%d field error(s): %s", len(children), strings.Join(descs, "; ")),
+		Hint:     "Fix the listed fields",
+		Plain:    true,
+		HttpCode: code,
+		Level:    lvl,
+		Cause:    errors.Join(errs...),
+	}, 1)
+	e.children = children
+	return e
+}
+
+// Children returns the field errors of an error built by Group, or nil for any other error.
+func (e *Error) Children() []FieldError {
+	if len(e.children) == 0 {
+		return nil
+	}
+	res := make([]FieldError, len(e.children))
+	copy(res, e.children)
+	return res
+}
+
+func aggregateCode(a, b int) int {
+	switch {
+	case a == b:
+		return a
+	case a >= http.StatusInternalServerError || b >= http.StatusInternalServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
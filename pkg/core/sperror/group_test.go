@@ -0,0 +1,137 @@
+package sperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"net/http"
+	"testing"
+)
+
+func ExampleGroup() {
+	var g Group
+	g.Add("email", New(Sample{
+		Messages: map[string]string{En: "Invalid email", Ru: "Некорректный email"},
+		Desc:     "email has no @",
+		HttpCode: http.StatusBadRequest,
+		Level:    levels.LevelUser,
+	}))
+	g.Add("age", nil)
+	g.Add("name", BadRequest("name is empty", "Provide a name"))
+
+	err := g.Err()
+	fmt.Println(err.Code(), err.Msg(En))
+	for _, c := range err.Children() {
+		fmt.Printf("%s: %s\n", c.Field, c.Err.Msg(Ru))
+	}
+	// Output:
+	// 400 Validation failed
+	// email: Некорректный email
+	// name:
+}
+
+func TestGroup_Err(t *testing.T) {
+	user := func(code int) *Error {
+		return New(Sample{Messages: map[string]string{En: "invalid"}, Desc: "invalid", HttpCode: code, Level: levels.LevelUser})
+	}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantNil   bool
+		wantCode  int
+		wantLevel levels.Level
+	}{
+		{
+			name:    "empty",
+			errs:    []error{nil},
+			wantNil: true,
+		},
+		{
+			name:      "same code",
+			errs:      []error{user(http.StatusUnprocessableEntity), user(http.StatusUnprocessableEntity)},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantLevel: levels.LevelUser,
+		},
+		{
+			name:      "client errors",
+			errs:      []error{user(http.StatusNotFound), user(http.StatusConflict)},
+			wantCode:  http.StatusBadRequest,
+			wantLevel: levels.LevelUser,
+		},
+		{
+			name:      "server error and plain error",
+			errs:      []error{user(http.StatusNotFound), errors.New("timeout"), user(http.StatusBadGateway)},
+			wantCode:  http.StatusInternalServerError,
+			wantLevel: levels.LevelError,
+		},
+		{
+			name:      "no codes",
+			errs:      []error{New(Sample{Desc: "a", Level: levels.LevelInfo})},
+			wantCode:  http.StatusBadRequest,
+			wantLevel: levels.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g Group
+			for i, err := range tt.errs {
+				g.Add(fmt.Sprintf("field%d", i), err)
+			}
+
+			got := g.Err()
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("Err() = %v, want nil %v", got, tt.wantNil)
+			}
+			if tt.wantNil {
+				return
+			}
+			if got.Code() != tt.wantCode || got.Level() != tt.wantLevel {
+				t.Errorf("Err() code = %v, level = %v, want %v, %v", got.Code(), got.Level(), tt.wantCode, tt.wantLevel)
+			}
+			if len(got.Children()) != g.Len() {
+				t.Errorf("Children() = %v, want %d children", got.Children(), g.Len())
+			}
+			for _, c := range got.Children() {
+				if !errors.Is(got, c.Err) {
+					t.Errorf("errors.Is(%s) = false, want true", c.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestGroup_Chain(t *testing.T) {
+	var g Group
+	g.Add("email", BadRequest("email has no @", "Fix email").SetErrCode("users.invalid_email"))
+	g.Add("tags[1]", errors.New("tag {x} is too long"))
+
+	err := WrapNew(g.Err(), Sample{Desc: "handler failed", Level: levels.LevelError})
+
+	if got := err.Spin(levels.LevelError); len(got.Children()) != 2 || got.Msg(En) != ErrValidation {
+		t.Errorf("Spin() = %v with children %v, want the group", got, got.Children())
+	}
+
+	b, e := json.Marshal(err)
+	if e != nil {
+		t.Fatalf("Marshal() error = %v", e)
+	}
+	decoded := &Error{}
+	if e = json.Unmarshal(b, decoded); e != nil {
+		t.Fatalf("Unmarshal() error = %v", e)
+	}
+
+	group := decoded.Spin(levels.LevelError)
+	children := group.Children()
+	if len(children) != 2 || children[0].Field != "email" || children[0].Err.ErrCode() != "users.invalid_email" {
+		t.Fatalf("decoded Children() = %v", children)
+	}
+	if group.Desc() != "2 field error(s): email: email has no @; tags[1]: tag {x} is too long" {
+		t.Errorf("decoded Desc() = %q", group.Desc())
+	}
+	if !errors.Is(decoded, children[1].Err) {
+		t.Errorf("errors.Is() = false, want true for a decoded child")
+	}
+}
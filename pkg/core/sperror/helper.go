@@ -6,13 +6,13 @@ import (
 )
 
 func Builder() *Error {
-	return New(Sample{
+	return newAt(Sample{
 		Level: levels.LevelError,
-	}).path(1)
+	}, 1)
 }
 
 func Any(caused error, desc, hint string) *Error {
-	err := formError(http.StatusInternalServerError, nil, ErrInternal, desc, hint).path(1)
+	err := formError(http.StatusInternalServerError, nil, ErrInternal, desc, hint)
 	switch v := caused.(type) {
 	case *Error:
 		return Wrap(v, err)
@@ -22,30 +22,31 @@ func Any(caused error, desc, hint string) *Error {
 }
 
 func Internal(caused error, desc, hint string) *Error {
-	return formError(http.StatusInternalServerError, caused, ErrInternal, desc, hint).path(1)
+	return formError(http.StatusInternalServerError, caused, ErrInternal, desc, hint)
 }
 
 func NotFound(desc, hint string) *Error {
-	return formError(http.StatusNotFound, nil, ErrNotFound, desc, hint).path(1)
+	return formError(http.StatusNotFound, nil, ErrNotFound, desc, hint)
 }
 
 func Forbidden(desc, hint string) *Error {
-	return formError(http.StatusForbidden, nil, ErrForbidden, desc, hint).path(1)
+	return formError(http.StatusForbidden, nil, ErrForbidden, desc, hint)
 }
 
 func BadRequest(desc, hint string) *Error {
-	return formError(http.StatusBadRequest, nil, ErrBadReq, desc, hint).path(1)
+	return formError(http.StatusBadRequest, nil, ErrBadReq, desc, hint)
 }
 
+// formError builds the error of a helper with the source set to the caller of the helper.
+// desc and hint of helpers are plain text, not templates.
 func formError(code int, err error, msg, desc, hint string) *Error {
-	return New(Sample{
-		Messages: map[string]string{
-			En: msg,
-		},
+	return newAt(Sample{
+		Messages: localized(msg),
 		Desc:     desc,
 		Hint:     hint,
+		Plain:    true,
 		HttpCode: code,
 		Level:    levels.LevelUser,
 		Cause:    err,
-	})
+	}, 2)
 }
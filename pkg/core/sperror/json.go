@@ -1,153 +1,238 @@
 package sperror
 
-// TODO: refactor
-//
-//func (e *Error) MarshalJSON() ([]byte, error) {
-//	w := jwriter.Writer{}
-//	e.MarshalEasyJSON(&w)
-//	return w.Buffer.BuildBytes(), w.Error
-//}
-//
-//func (e *Error) MarshalEasyJSON(w *jwriter.Writer) {
-//	w.RawByte('{')
-//	w.RawString(`"messages":`)
-//	if e.messages == nil {
-//		w.RawString(`null`)
-//	} else {
-//		w.RawByte('{')
-//		v1First := true
-//		for v1Name, v1Value := range e.messages {
-//			if !v1First {
-//				w.RawByte(',')
-//			}
-//			v1First = false
-//			w.String(v1Name)
-//			w.RawByte(':')
-//			w.String(v1Value)
-//		}
-//		w.RawByte('}')
-//	}
-//	w.RawByte(',')
-//	w.RawString(`"description":`)
-//	w.String(e.desc)
-//	w.RawByte(',')
-//	w.RawString(`"hint":`)
-//	w.String(e.hint)
-//	w.RawByte(',')
-//	w.RawString(`"source":`)
-//	w.String(e.source)
-//
-//	if e.httpCode != 0 {
-//		w.RawByte(',')
-//		w.RawString(`"http_code":`)
-//		w.Int(e.httpCode)
-//	}
-//
-//	w.RawByte(',')
-//	w.RawString(`"level":`)
-//	w.Int(int(e.level))
-//
-//	if len(e.meta) != 0 {
-//		w.RawByte(',')
-//		w.RawString(`"meta":`)
-//		if e.meta == nil {
-//			w.RawString(`null`)
-//		} else {
-//			w.RawByte('{')
-//			v2First := true
-//			for v2Name, v2Value := range e.meta {
-//				if !v2First {
-//					w.RawByte(',')
-//				}
-//				v2First = false
-//				w.String(v2Name)
-//				w.RawByte(':')
-//				w.Raw(json.Marshal(v2Value))
-//			}
-//			w.RawByte('}')
-//		}
-//	}
-//
-//	w.RawByte('}')
-//}
-//
-//func (e *Error) UnmarshalJSON(data []byte) error {
-//	r := jlexer.Lexer{Data: data}
-//	e.UnmarshalEasyJSON(&r)
-//	return r.Error()
-//}
-//
-//func (e *Error) UnmarshalEasyJSON(in *jlexer.Lexer) {
-//	if in.IsNull() {
-//		in.Skip()
-//		return
-//	}
-//	in.Delim('{')
-//	for !in.IsDelim('}') {
-//		key := in.UnsafeFieldName(false)
-//		in.WantColon()
-//		if in.IsNull() {
-//			in.Skip()
-//			in.WantComma()
-//			continue
-//		}
-//		switch key {
-//		case "messages":
-//			if in.IsNull() {
-//				in.Skip()
-//			} else {
-//				in.Delim('{')
-//				if e.messages == nil {
-//					e.messages = make(map[string]string)
-//				}
-//				for !in.IsDelim('}') {
-//					key := in.String()
-//					in.WantColon()
-//					var v1 string
-//					v1 = in.String()
-//					(e.messages)[key] = v1
-//					in.WantComma()
-//				}
-//				in.Delim('}')
-//			}
-//		case "description":
-//			e.desc = in.String()
-//		case "hint":
-//			e.hint = in.String()
-//		case "source":
-//			e.source = in.String()
-//		case "http_code":
-//			e.httpCode = in.Int()
-//		case "level":
-//			e.level = levels.Level(in.Int())
-//		case "meta":
-//			if in.IsNull() {
-//				in.Skip()
-//			} else {
-//				in.Delim('{')
-//				if e.meta == nil {
-//					e.meta = make(map[string]interface{})
-//				}
-//				for !in.IsDelim('}') {
-//					key := in.String()
-//					in.WantColon()
-//					var v2 interface{}
-//					if m, ok := v2.(easyjson.Unmarshaler); ok {
-//						m.UnmarshalEasyJSON(in)
-//					} else if m, ok := v2.(json.Unmarshaler); ok {
-//						in.AddError(m.UnmarshalJSON(in.Raw()))
-//					} else {
-//						v2 = in.Interface()
-//					}
-//					(e.meta)[key] = v2
-//					in.WantComma()
-//				}
-//				in.Delim('}')
-//			}
-//		default:
-//			in.SkipRecursive()
-//		}
-//		in.WantComma()
-//	}
-//	in.Delim('}')
-//}
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+)
+
+type (
+	// jsonError is the wire representation of a single Error layer.
+	// Underlying layers are nested recursively, so the whole Wrap chain travels in one document.
+	jsonError struct {
+		Code       string            `json:"code,omitempty"`
+		Messages   map[string]string `json:"messages"`
+		Desc       string            `json:"description"`
+		Hint       string            `json:"hint"`
+		Source     string            `json:"source"`
+		Plain      bool              `json:"plain,omitempty"`
+		HttpCode   int               `json:"http_code,omitempty"`
+		Level      levels.Level      `json:"level"`
+		Meta       map[string]any    `json:"meta,omitempty"`
+		Cause      json.RawMessage   `json:"cause,omitempty"`
+		Stack      StackTrace        `json:"stack,omitempty"`
+		Children   []jsonChild       `json:"children,omitempty"`
+		Retry      *jsonRetry        `json:"retry,omitempty"`
+		Underlying *Error            `json:"underlying,omitempty"`
+	}
+
+	// jsonRetry is the wire representation of an explicit retry classification.
+	jsonRetry struct {
+		Retryable    bool  `json:"retryable"`
+		Temporary    bool  `json:"temporary"`
+		Timeout      bool  `json:"timeout"`
+		RetryAfterMs int64 `json:"retry_after_ms,omitempty"`
+	}
+
+	// jsonChild is the wire representation of a FieldError.
+	jsonChild struct {
+		Field string `json:"field"`
+		Error *Error `json:"error"`
+	}
+
+	// textError replaces a foreign cause after decoding.
+	// Only the text of such errors survives serialization, so it matches any error with the same text.
+	textError struct {
+		msg string
+	}
+)
+
+// TextError returns an error with the text msg that matches any error with the same text, see errors.Is.
+// Decoders restore causes that were not *Error values with it, since only their text survives serialization.
+func TextError(msg string) error {
+	return &textError{msg: msg}
+}
+
+// Restore builds a decoded layer from s. Unlike New, it neither sets the source nor captures the stack
+// of the caller: source and st are taken as decoded. children become field errors as in Group, and their
+// join becomes the cause. Decoders of other formats use it with Wrap and SetRetryClass, as UnmarshalJSON does.
+func Restore(s Sample, source string, st StackTrace, children ...FieldError) *Error {
+	e := build(s)
+	e.Core.Source = source
+	if len(st) != 0 {
+		e.stack = &stack{frames: slices.Clone(st)}
+	}
+	if len(children) != 0 {
+		e.restoreChildren(children)
+	}
+	return e
+}
+
+// restoreChildren sets decoded field errors and their join as the cause, missing errors become empty ones.
+func (e *Error) restoreChildren(children []FieldError) {
+	errs := make([]error, 0, len(children))
+	for _, c := range children {
+		if c.Err == nil {
+			c.Err = NewSpErr()
+		}
+		e.children = append(e.children, c)
+		errs = append(errs, c.Err)
+	}
+	e.Core.Cause = errors.Join(errs...)
+}
+
+func (t *textError) Error() string {
+	return t.msg
+}
+
+// Is reports whether err has the same text as the decoded cause.
+func (t *textError) Is(err error) bool {
+	return err != nil && err.Error() == t.msg
+}
+
+// MarshalJSON encodes the Error together with its meta, cause and the whole Wrap chain.
+// A *Error cause is encoded as a nested object, any other cause is encoded as its Error() text.
+// Field errors of an error built by Group are encoded as a nested "children" list instead of the cause.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+
+	out := jsonError{
+		Code:       e.User.Code,
+		Messages:   e.User.Messages,
+		Desc:       e.Core.Desc,
+		Hint:       e.Core.Hint,
+		Source:     e.Core.Source,
+		Plain:      e.plain,
+		HttpCode:   e.User.HttpCode,
+		Level:      e.User.Level,
+		Meta:       e.meta,
+		Stack:      e.StackTrace(),
+		Underlying: e.underlying,
+	}
+
+	if r := e.retry; r.set {
+		out.Retry = &jsonRetry{
+			Retryable:    r.retryable,
+			Temporary:    r.temporary,
+			Timeout:      r.timeout,
+			RetryAfterMs: r.after.Milliseconds(),
+		}
+	}
+
+	// messages are always encoded as an object, even if they are not allocated yet
+	if out.Messages == nil {
+		out.Messages = map[string]string{}
+	}
+
+	// the cause of a group is the join of its children, so it is rebuilt from them on decoding
+	for _, c := range e.children {
+		out.Children = append(out.Children, jsonChild{Field: c.Field, Error: c.Err})
+	}
+
+	switch v := e.Core.Cause.(type) {
+	case nil:
+	case *Error:
+		cause, err := v.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		out.Cause = cause
+	default:
+		if len(e.children) != 0 {
+			break
+		}
+		cause, err := json.Marshal(v.Error())
+		if err != nil {
+			return nil, err
+		}
+		out.Cause = cause
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes an Error produced by MarshalJSON, restoring the Wrap chain.
+// Causes that were not *Error values are restored as errors that match the original by text.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var in jsonError
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*e = Error{
+		Core: CoreError{
+			Desc:   in.Desc,
+			Hint:   in.Hint,
+			Source: in.Source,
+		},
+		User: UserError{
+			HttpCode: in.HttpCode,
+			Level:    in.Level,
+			Code:     in.Code,
+		},
+		plain: in.Plain,
+	}
+	// decoded maps are not shared with anything, so they are used as is; empty ones are left nil, as in New
+	if len(in.Messages) != 0 {
+		e.User.Messages = in.Messages
+	}
+	if len(in.Meta) != 0 {
+		e.meta = in.Meta
+	}
+
+	if r := in.Retry; r != nil {
+		e.retry = retryInfo{
+			set:       true,
+			retryable: r.Retryable,
+			temporary: r.Temporary,
+			timeout:   r.Timeout,
+			after:     time.Duration(r.RetryAfterMs) * time.Millisecond,
+		}
+	}
+
+	if len(in.Stack) != 0 {
+		e.stack = &stack{frames: in.Stack}
+	}
+
+	if in.Underlying != nil {
+		e.underlying = in.Underlying
+		e.remainsUnderlying = in.Underlying.remainsUnderlying + 1
+	}
+
+	if len(in.Children) != 0 {
+		children := make([]FieldError, len(in.Children))
+		for i, c := range in.Children {
+			children[i] = FieldError{Field: c.Field, Err: c.Error}
+		}
+		e.restoreChildren(children)
+	}
+
+	cause := bytes.TrimSpace(in.Cause)
+	switch {
+	case len(cause) == 0 || bytes.Equal(cause, []byte("null")):
+	case cause[0] == '{':
+		nested := &Error{}
+		if err := nested.UnmarshalJSON(cause); err != nil {
+			return err
+		}
+		e.Core.Cause = nested
+	default:
+		var msg string
+		if err := json.Unmarshal(cause, &msg); err != nil {
+			return err
+		}
+		e.Core.Cause = TextError(msg)
+	}
+
+	return nil
+}
@@ -1,81 +1,163 @@
 package sperror
 
-// TODO: better tests and benchmarks
-//
-//func TestMarshalEasyJSON(t *testing.T) {
-//	tests := []struct {
-//		name    string
-//		spErr   *Error
-//		want    string
-//		wantErr bool
-//	}{
-//		{
-//			name: "nil_fields",
-//			spErr: &Error{
-//				messages: nil,
-//				meta:     nil,
-//			},
-//			want:    `{"messages":null,"description":"","hint":"","http_code":0,"level":0,"meta":null}`,
-//			wantErr: false,
-//		},
-//		{
-//			name: "populated_fields",
-//			spErr: &Error{
-//				messages: map[string]string{"en": "error message", "fr": "message d'erreur"},
-//				desc:     "Test description",
-//				hint:     "Test hint",
-//				httpCode: 404,
-//				level:    levels.LevelInfo,
-//				meta:     map[string]any{"key1": "value1", "key2": 123},
-//			},
-//			want:    `{"messages":{"en":"error message","fr":"message d'erreur"},"description":"Test description","hint":"Test hint","http_code":404,"level":8,"meta":{"key1":"value1","key2":123}}`,
-//			wantErr: false,
-//		},
-//		{
-//			name: "empty_messages",
-//			spErr: &Error{
-//				messages: map[string]string{},
-//				desc:     "Empty messages",
-//				meta:     map[string]any{"key": "value"},
-//			},
-//			want:    `{"messages":{},"description":"Empty messages","hint":"","http_code":0,"level":0,"meta":{"key":"value"}}`,
-//			wantErr: false,
-//		},
-//		{
-//			name: "meta_is_empty_map",
-//			spErr: &Error{
-//				messages: map[string]string{"en": "error"},
-//				meta:     map[string]any{},
-//			},
-//			want:    `{"messages":{"en":"error"},"description":"","hint":"","http_code":0,"level":0,"meta":{}}`,
-//			wantErr: false,
-//		},
-//		{
-//			name: "special_characters_in_messages",
-//			spErr: &Error{
-//				messages: map[string]string{"en": "Error \"message\" with special JSON: {} [] chars"},
-//				desc:     "Special characters description",
-//				hint:     "Special characters hint",
-//			},
-//			want:    `{"messages":{"en":"Error \"message\" with special JSON: {} [] chars"},"description":"Special characters description","hint":"Special characters hint","http_code":0,"level":0,"meta":null}`,
-//			wantErr: false,
-//		},
-//	}
-//
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			var w jwriter.Writer
-//			tt.spErr.MarshalEasyJSON(&w)
-//
-//			if (w.Error != nil) != tt.wantErr {
-//				t.Errorf("MarshalEasyJSON() error = %v, wantErr %v", w.Error, tt.wantErr)
-//				return
-//			}
-//
-//			got := string(w.Buffer.BuildBytes())
-//			if got != tt.want {
-//				t.Errorf("MarshalEasyJSON() = %v, want %v", got, tt.want)
-//			}
-//		})
-//	}
-//}
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"reflect"
+	"testing"
+)
+
+func ExampleError_MarshalJSON() {
+	err := New(Sample{
+		Messages: map[string]string{
+			En: "Not found",
+		},
+		Desc:     "User not found",
+		Hint:     "Check user id",
+		HttpCode: 404,
+		Level:    levels.LevelUser,
+		Meta:     map[string]any{"user_id": 42},
+	})
+	err.Core.Source = "user.go:10"
+
+	b, _ := json.Marshal(err)
+	fmt.Println(string(b))
+	// Output:
+	// {"messages":{"en":"Not found"},"description":"User not found","hint":"Check user id","source":"user.go:10","http_code":404,"level":2,"meta":{"user_id":42}}
+}
+
+func TestError_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+	}{
+		{
+			name: "single layer",
+			err: New(Sample{
+				Code:     "test.error",
+				Messages: map[string]string{En: "Test error", Ru: "Тестовая ошибка"},
+				Desc:     "test description",
+				Hint:     "test hint",
+				HttpCode: 500,
+				Level:    levels.LevelError,
+				Meta:     map[string]any{"key": "value"},
+			}),
+		},
+		{
+			name: "foreign cause",
+			err:  Any(sql.ErrNoRows, "query failed", "check query"),
+		},
+		{
+			name: "error cause",
+			err:  New(Sample{Desc: "outer"}).Wrap(New(Sample{Desc: "inner", Cause: sql.ErrNoRows})),
+		},
+		{
+			name: "wrap chain",
+			err:  Api(),
+		},
+		{
+			name: "nested chains",
+			err:  Any(Any(BadRequest("1", "1"), "2", "2"), "3", "3"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.err)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			got := &Error{}
+			if err = json.Unmarshal(b, got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			again, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(again) != string(b) {
+				t.Errorf("round trip = %s, want %s", again, b)
+			}
+
+			for _, lvl := range []levels.Level{levels.LevelUser, levels.LevelInfo, levels.LevelError, levels.LevelDebug} {
+				want, have := tt.err.Spin(lvl), got.Spin(lvl)
+				if want.Desc() != have.Desc() || want.Source() != have.Source() || want.Level() != have.Level() || want.ErrCode() != have.ErrCode() {
+					t.Errorf("Spin(%v) = %q, want %q", lvl, have.Desc(), want.Desc())
+				}
+			}
+
+			if tt.err.DeepIs(sql.ErrNoRows) != got.DeepIs(sql.ErrNoRows) {
+				t.Errorf("DeepIs() = %v, want %v", got.DeepIs(sql.ErrNoRows), tt.err.DeepIs(sql.ErrNoRows))
+			}
+
+			want, have := tt.err.Unwrap(), got.Unwrap()
+			if len(want) != len(have) {
+				t.Fatalf("Unwrap() = %v, want %v", have, want)
+			}
+			for i := range want {
+				if reflect.TypeOf(want[i]) == reflect.TypeOf(&Error{}) && reflect.TypeOf(have[i]) != reflect.TypeOf(want[i]) {
+					t.Errorf("Unwrap()[%d] type = %T, want %T", i, have[i], want[i])
+				}
+				if want[i].Error() != have[i].Error() {
+					t.Errorf("Unwrap()[%d] = %v, want %v", i, have[i], want[i])
+				}
+			}
+
+			if !reflect.DeepEqual(tt.err.AllMeta(), got.AllMeta()) {
+				t.Errorf("AllMeta() = %v, want %v", got.AllMeta(), tt.err.AllMeta())
+			}
+		})
+	}
+}
+
+func TestError_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *Error
+		wantErr bool
+	}{
+		{
+			name: "null",
+			data: `null`,
+			want: &Error{},
+		},
+		{
+			name: "text cause",
+			data: `{"messages":{"en":"a"},"description":"b","hint":"c","source":"d","level":64,"cause":"sql: no rows in result set"}`,
+			want: &Error{
+				Core: CoreError{Desc: "b", Hint: "c", Source: "d", Cause: &textError{msg: sql.ErrNoRows.Error()}},
+				User: UserError{Messages: map[string]string{En: "a"}, Level: levels.LevelError},
+			},
+		},
+		{
+			name:    "malformed",
+			data:    `{"messages":1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &Error{}
+			err := json.Unmarshal([]byte(tt.data), got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %#v, want %#v", got, tt.want)
+			}
+			if got.Caused() != nil && !errors.Is(got.Caused(), sql.ErrNoRows) {
+				t.Errorf("Caused() = %v, want match with %v", got.Caused(), sql.ErrNoRows)
+			}
+		})
+	}
+}
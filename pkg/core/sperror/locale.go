@@ -0,0 +1,168 @@
+package sperror
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/s4bb4t/lighthouse/internal/header"
+)
+
+var locale = struct {
+	sync.RWMutex
+	def       string
+	fallbacks map[string][]string
+}{
+	def: En,
+	fallbacks: map[string][]string{
+		Cs:   {Cz},
+		Cz:   {Cs},
+		"nb": {No},
+		"nn": {No},
+	},
+}
+
+// builtin holds translations of messages used by Ensure and the helpers.
+var builtin = map[string]map[string]string{
+	ErrNotFound: {
+		Ru: "Не найдено", Uk: "Не знайдено", De: "Nicht gefunden", Fr: "Introuvable", Es: "No encontrado",
+		Pt: "Não encontrado", It: "Non trovato", Pl: "Nie znaleziono",
+	},
+	ErrInternal: {
+		Ru: "Внутренняя ошибка сервера", Uk: "Внутрішня помилка сервера", De: "Interner Serverfehler",
+		Fr: "Erreur interne du serveur", Es: "Error interno del servidor", Pt: "Erro interno do servidor",
+		It: "Errore interno del server", Pl: "Wewnętrzny błąd serwera",
+	},
+	ErrBadReq: {
+		Ru: "Некорректный запрос", Uk: "Некоректний запит", De: "Ungültige Anfrage", Fr: "Requête invalide",
+		Es: "Solicitud incorrecta", Pt: "Requisição inválida", It: "Richiesta non valida", Pl: "Nieprawidłowe żądanie",
+	},
+	ErrForbidden: {
+		Ru: "Доступ запрещён", Uk: "Доступ заборонено", De: "Zugriff verweigert", Fr: "Accès interdit",
+		Es: "Acceso prohibido", Pt: "Acesso proibido", It: "Accesso negato", Pl: "Brak dostępu",
+	},
+	ErrUnknown: {
+		Ru: "Неизвестная ошибка", Uk: "Невідома помилка", De: "Unbekannter Fehler", Fr: "Erreur inconnue",
+		Es: "Error desconocido", Pt: "Erro desconhecido", It: "Errore sconosciuto", Pl: "Nieznany błąd",
+	},
+	ErrValidation: {
+		Ru: "Ошибка валидации", Uk: "Помилка валідації", De: "Validierung fehlgeschlagen", Fr: "Échec de la validation",
+		Es: "Error de validación", Pt: "Falha na validação", It: "Convalida non riuscita", Pl: "Błąd walidacji",
+	},
+}
+
+// SetDefaultLang sets the package-level default language.
+//
+// It is the last language MsgFor tries before English, and Ensure and the helpers (NotFound, Internal, etc.)
+// add the translation of their message for it next to the English one.
+func SetDefaultLang(lg string) {
+	locale.Lock()
+	defer locale.Unlock()
+	locale.def = normalizeLang(lg)
+}
+
+// DefaultLang returns the package-level default language, En unless changed with SetDefaultLang.
+func DefaultLang() string {
+	locale.RLock()
+	defer locale.RUnlock()
+	return locale.def
+}
+
+// SetFallback sets the languages MsgFor tries, in order, when there is no message for lg.
+//
+// For example, SetFallback(Uk, Ru) makes Ukrainian users see the Russian message if there is no Ukrainian one,
+// and English only after that. Calling it without fallbacks removes the chain of lg.
+func SetFallback(lg string, fallbacks ...string) {
+	locale.Lock()
+	defer locale.Unlock()
+
+	lg = normalizeLang(lg)
+	if len(fallbacks) == 0 {
+		delete(locale.fallbacks, lg)
+		return
+	}
+	chain := make([]string, len(fallbacks))
+	for i, f := range fallbacks {
+		chain[i] = normalizeLang(f)
+	}
+	locale.fallbacks[lg] = chain
+}
+
+// ParseAcceptLanguage parses an Accept-Language style priority list, e.g. "pt-BR,pt;q=0.9,en;q=0.5",
+// and returns its language tags ordered by descending quality.
+// Tags are lower-cased, the wildcard and tags with q=0 are dropped.
+func ParseAcceptLanguage(accept string) []string {
+	tags := header.Weighted(accept)
+	res := tags[:0]
+	for _, lg := range tags {
+		if lg = normalizeLang(lg); lg != "*" {
+			res = append(res, lg)
+		}
+	}
+	return res
+}
+
+// MsgFor returns the message in the best available language.
+//
+// Every argument is a language tag or an Accept-Language style list. For each requested tag MsgFor tries
+// the tag itself, its primary language (pt-BR → pt) and the fallback chain set by SetFallback.
+// Then it tries the default language, English and finally any message, so the result is blank only
+// if the error has no messages at all.
+func (e *Error) MsgFor(langs ...string) string {
+	return e.Msg(e.LangFor(langs...))
+}
+
+// LangFor returns the language of the message MsgFor would return, e.g. for a Content-Language header.
+// It returns an empty string if the error has no messages.
+func (e *Error) LangFor(langs ...string) string {
+	has := func(lg string) bool {
+		return e.User.Messages[lg] != ""
+	}
+
+	locale.RLock()
+	def, fallbacks := locale.def, locale.fallbacks
+	for _, arg := range langs {
+		for _, lg := range ParseAcceptLanguage(arg) {
+			primary, _, _ := strings.Cut(lg, "-")
+			for _, c := range slices.Concat([]string{lg, primary}, fallbacks[lg], fallbacks[primary]) {
+				if has(c) {
+					locale.RUnlock()
+					return c
+				}
+			}
+		}
+	}
+	locale.RUnlock()
+
+	for _, c := range []string{def, En} {
+		if has(c) {
+			return c
+		}
+	}
+
+	var keys []string
+	for lg := range e.User.Messages {
+		if has(lg) {
+			keys = append(keys, lg)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return slices.Min(keys)
+}
+
+// localized returns msg in English and in the default language, if there is a translation for it.
+func localized(msg string) map[string]string {
+	res := map[string]string{En: msg}
+	if def := DefaultLang(); def != En {
+		if tr, ok := builtin[msg][def]; ok {
+			res[def] = tr
+		}
+	}
+	return res
+}
+
+func normalizeLang(lg string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lg), "_", "-"))
+}
@@ -0,0 +1,101 @@
+package sperror
+
+import (
+	"errors"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{header: "", want: []string{}},
+		{header: "uk", want: []string{Uk}},
+		{header: "pt-BR,pt;q=0.9,en;q=0.5", want: []string{"pt-br", Pt, En}},
+		{header: "en;q=0.1, fr;q=0, *;q=0.5, pt_BR", want: []string{"pt-br", En}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceptLanguage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_MsgFor(t *testing.T) {
+	SetFallback(Uk, Ru)
+	defer SetFallback(Uk)
+
+	err := New(Sample{
+		Messages: map[string]string{
+			En: "Not found",
+			Ru: "Не найдено",
+			Pt: "Não encontrado",
+			Cz: "Nenalezeno",
+		},
+		Level: levels.LevelUser,
+	})
+
+	tests := []struct {
+		name  string
+		err   *Error
+		langs []string
+		want  string
+	}{
+		{name: "exact", err: err, langs: []string{Ru}, want: "Не найдено"},
+		{name: "fallback chain", err: err, langs: []string{Uk}, want: "Не найдено"},
+		{name: "region", err: err, langs: []string{"pt-BR"}, want: "Não encontrado"},
+		{name: "alias", err: err, langs: []string{Cs}, want: "Nenalezeno"},
+		{name: "priority list", err: err, langs: []string{"de-DE,de;q=0.9,pt;q=0.8,ru;q=0.7"}, want: "Não encontrado"},
+		{name: "several arguments", err: err, langs: []string{De, Ru}, want: "Не найдено"},
+		{name: "english", err: err, langs: []string{Ja}, want: "Not found"},
+		{name: "any", err: New(Sample{Messages: map[string]string{De: "Nicht gefunden", Fr: "Introuvable"}}), langs: []string{Ja}, want: "Nicht gefunden"},
+		{name: "none", err: NewSpErr(), langs: []string{En}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.MsgFor(tt.langs...); got != tt.want {
+				t.Errorf("MsgFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDefaultLang(t *testing.T) {
+	SetDefaultLang(Ru)
+	defer SetDefaultLang(En)
+
+	tests := []struct {
+		name string
+		err  *Error
+		want map[string]string
+	}{
+		{
+			name: "ensure",
+			err:  Ensure(errors.New("plain")),
+			want: map[string]string{En: ErrUnknown, Ru: "Неизвестная ошибка"},
+		},
+		{
+			name: "helper",
+			err:  NotFound("desc", "hint"),
+			want: map[string]string{En: ErrNotFound, Ru: "Не найдено"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.err.User.Messages, tt.want) {
+				t.Errorf("Messages = %v, want %v", tt.err.User.Messages, tt.want)
+			}
+			if got := tt.err.MsgFor(Ja); got != tt.want[Ru] {
+				t.Errorf("MsgFor() = %v, want default language %v", got, tt.want[Ru])
+			}
+		})
+	}
+}
@@ -3,25 +3,42 @@ package sperror
 import (
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 	"maps"
+	"slices"
+	"strings"
 )
 
 // Error returns the Error's description.
 func (e *Error) Error() string {
-	if e.Core.Hint != "" {
-		return e.Core.Desc + ": " + e.Core.Hint
+	if hint := e.Hint(); hint != "" {
+		return e.Desc() + ": " + hint
 	}
 
-	return e.Core.Desc
+	return e.Desc()
 }
 
-// Copy returns a copy of the Error instance.
+// Copy returns a deep copy of the Error instance: messages, meta, field errors and the whole Wrap chain are copied,
+// so modifying the copy never affects e. *Error causes are copied as well, other causes are shared.
+// The copy is not frozen even if e is, see Freeze.
 func (e *Error) Copy() *Error {
-	var err Error
-	err = *e
+	if e == nil {
+		return nil
+	}
+
+	err := *e
+	err.frozen = false
+	err.User.Messages = maps.Clone(e.User.Messages)
+	err.meta = copyMeta(e.meta)
+	if cause, ok := e.Core.Cause.(*Error); ok {
+		err.Core.Cause = cause.Copy()
+	}
+	if len(e.children) != 0 {
+		err.children, err.Core.Cause = copyChildren(e.children)
+	}
+	err.underlying = e.underlying.Copy()
 	return &err
 }
 
-// Copy returns a copy of the Error instance.
+// Copy returns a deep copy of the Error instance, see Error.Copy.
 func Copy(e *Error) *Error {
 	return e.Copy()
 }
@@ -30,17 +47,46 @@ func Copy(e *Error) *Error {
 // If the error is already of the *Error type, it is returned as-is.
 // This method is useful for ensuring that an error is of the *Error type.
 // It is recommended to use this method instead of casting the error to an *Error type directly.
+//
+// A multi-error, e.g. one built with errors.Join, that holds a single error is ensured as that error.
+// Otherwise it becomes the cause of the new error, which gets the highest level and HTTP code of its members,
+// so Spin never shows it at a level where one of the members would be hidden.
+//
+// The new error is classified for retries from the original one, see Error.Retryable.
 func Ensure(err error) *Error {
-	if sperr, ok := err.(*Error); ok {
-		return sperr
+	for {
+		if sperr, ok := err.(*Error); ok {
+			return sperr
+		}
+		multi, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(multi.Unwrap()) != 1 {
+			break
+		}
+		err = multi.Unwrap()[0]
 	}
-	return New(Sample{
-		Messages: map[string]string{En: "Unknown error"},
+
+	lvl, code := levels.LevelError, 0
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, member := range multi.Unwrap() {
+			if sperr, ok := member.(*Error); ok {
+				lvl = max(lvl, sperr.User.Level)
+				code = max(code, sperr.User.HttpCode)
+			}
+		}
+	}
+
+	res := newAt(Sample{
 		Desc:     err.Error(),
 		Hint:     "Check original .Error()",
-		Level:    levels.LevelError,
+		Plain:    true,
+		HttpCode: code,
+		Level:    lvl,
 		Cause:    err,
-	}).path(1)
+	}, 1)
+	// localized returns a fresh map, so it is not copied by New
+	res.User.Messages = localized(ErrUnknown)
+	res.retry = inferRetry(err)
+	return res
 }
 
 // AllMeta returns a copy of all metadata associated with the error.
@@ -62,20 +108,68 @@ func (e *Error) Caused() error {
 
 // Msg returns the error message for the specified language code.
 // Parameter lg represents the language code to retrieve the message for.
+// It returns an empty string if there is no such message, use MsgFor to fall back to other languages.
+// The message is rendered as a template with the error's meta, see MissingParams.
 func (e *Error) Msg(lg string) string {
-	return e.User.Messages[lg]
+	msg, _ := e.render(e.User.Messages[lg], lg)
+	return msg
 }
 
 // Desc returns the description of the error.
 // The description provides additional context about the error.
+// It is rendered as a template with the error's meta using plural rules of the default language.
 func (e *Error) Desc() string {
-	return e.Core.Desc
+	desc, _ := e.render(e.Core.Desc, DefaultLang())
+	return desc
 }
 
 // Hint returns a hint or suggestion related to resolving the error.
 // The hint provides guidance on how to fix or handle the error.
+// It is rendered as a template with the error's meta using plural rules of the default language.
 func (e *Error) Hint() string {
-	return e.Core.Hint
+	hint, _ := e.render(e.Core.Hint, DefaultLang())
+	return hint
+}
+
+// MissingParams returns the names of template parameters used by the messages, the description
+// and the hint that are missing from meta, in order of appearance and without duplicates.
+// See template.go for the template syntax; errors of plain texts (see Sample.Plain) have no parameters.
+//
+// Usage:
+//
+//	var ErrTooLarge = sperror.New(sperror.Sample{
+//		Messages: map[string]string{
+//			sperror.En: "File {file} is larger than {limit} MB",
+//			sperror.Ru: "Файл {file} больше {limit, plural, one {# мегабайта} other {# мегабайт}}",
+//		},
+//	})
+//
+//	err := ErrTooLarge.AddMeta("file", "report.pdf")
+//	err.MissingParams() // [limit]
+func (e *Error) MissingParams() []string {
+	var res []string
+	add := func(_ string, missing []string) {
+		for _, m := range missing {
+			if !slices.Contains(res, m) {
+				res = append(res, m)
+			}
+		}
+	}
+
+	// a single message needs no sorting, which saves an allocation on the common path
+	if len(e.User.Messages) == 1 {
+		for lg, msg := range e.User.Messages {
+			add(e.render(msg, lg))
+		}
+	} else {
+		langs := slices.Sorted(maps.Keys(e.User.Messages))
+		for _, lg := range langs {
+			add(e.render(e.User.Messages[lg], lg))
+		}
+	}
+	add(e.render(e.Core.Desc, DefaultLang()))
+	add(e.render(e.Core.Hint, DefaultLang()))
+	return res
 }
 
 // Code returns the HTTP status code associated with the error.
@@ -84,6 +178,12 @@ func (e *Error) Code() int {
 	return e.User.HttpCode
 }
 
+// ErrCode returns the stable machine-readable code of the error, see SetErrCode.
+// It is empty if the error has no code.
+func (e *Error) ErrCode() string {
+	return e.User.Code
+}
+
 // Level returns the severity level of the error.
 // The level indicates how critical or severe the error is.
 func (e *Error) Level() levels.Level {
@@ -103,3 +203,15 @@ func (e *Error) Meta(key string) any {
 func (e *Error) Source() string {
 	return e.Core.Source
 }
+
+var templateEscaper = strings.NewReplacer("{", "{{", "}", "}}")
+
+// EscapeTemplate escapes the braces of s, so it is rendered as is when used as a message, a description or a hint.
+// Use it to embed arbitrary text, e.g. errors of other packages or remote input, in templates.
+// Texts that hold no template at all are better marked with Sample.Plain, so they are stored as they are.
+func EscapeTemplate(s string) string {
+	if !strings.ContainsAny(s, "{}") {
+		return s
+	}
+	return templateEscaper.Replace(s)
+}
@@ -52,6 +52,60 @@ func TestEnsure(t *testing.T) {
 	}
 }
 
+func TestEnsure_Join(t *testing.T) {
+	user := BadRequest("bad email", "check email")
+	debug := New(Sample{Desc: "connection reset", HttpCode: 503, Level: levels.LevelDebug})
+	caused := Internal(errors.New("db closed"), "query failed", "check db")
+
+	tests := []struct {
+		name      string
+		err       error
+		wantLevel levels.Level
+		wantCode  int
+		wantSame  *Error
+	}{
+		{
+			name:     "error with a single cause",
+			err:      caused,
+			wantSame: caused,
+		},
+		{
+			name:     "single member",
+			err:      errors.Join(nil, user),
+			wantSame: user,
+		},
+		{
+			name:      "plain members",
+			err:       errors.Join(errors.New("a"), errors.New("b")),
+			wantLevel: levels.LevelError,
+		},
+		{
+			name:      "highest level and code",
+			err:       errors.Join(user, debug, errors.New("plain")),
+			wantLevel: levels.LevelDebug,
+			wantCode:  503,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Ensure(tt.err)
+			if tt.wantSame != nil {
+				if got != tt.wantSame {
+					t.Errorf("Ensure() = %v, want %v", got, tt.wantSame)
+				}
+				return
+			}
+			if got.Level() != tt.wantLevel || got.Code() != tt.wantCode {
+				t.Errorf("Ensure() level = %v, code = %v, want %v, %v", got.Level(), got.Code(), tt.wantLevel, tt.wantCode)
+			}
+			if got.Desc() != tt.err.Error() || !errors.Is(got, tt.err) {
+				t.Errorf("Ensure() = %v, want cause %v", got, tt.err)
+			}
+		})
+	}
+}
+
 func TestError_AllMeta(t *testing.T) {
 	type fields struct {
 		Core              CoreError
@@ -0,0 +1,212 @@
+package sperror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Mask is the default replacement of redacted values.
+const Mask = "[REDACTED]"
+
+type (
+	// Policy describes what to redact from an error before it leaves the service: logs, alerts and exports.
+	//
+	// Every sink has its own policy, e.g. logs may keep hashes to correlate values while alerts mask everything.
+	//
+	// Meta keys are split into segments at '_', '-', '.' and camelCase boundaries. A key is redacted
+	// if it ends with the segments of one of Keys: "token" matches "access_token", "X-Access-Token"
+	// and "accessToken", but neither "tokens_used" nor "token_type".
+	Policy struct {
+		Keys     []string         // meta keys to redact, case-insensitive, matched by their last segments
+		Patterns []*regexp.Regexp // matches are redacted in causes, descriptions, hints and string meta values
+		Hash     bool             // replace values with a salted SHA-256 hash instead of Mask, so equal values can still be correlated
+		Salt     string           // salt of the hash
+		Mask     string           // replacement of redacted values, Mask if empty
+	}
+
+	// Sensitive wraps a meta value that must never be printed as is.
+	// It is always redacted by Redact and renders as Mask when formatted, logged or marshaled without a policy.
+	//
+	// Usage:
+	//
+	//	err.AddMeta("card", sperror.Sensitive{Value: card.Number})
+	Sensitive struct {
+		Value any
+	}
+)
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]+=*`)
+	dsnPattern    = regexp.MustCompile(`[^:/@\s]+:[^@/\s]+@`)
+)
+
+// DefaultPolicy returns a policy masking common secrets: passwords, tokens, keys, cookies and
+// authorization meta values, and emails, bearer tokens and credentials of URLs in texts.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Keys: []string{
+			"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "apikey", "private_key", "session", "session_id",
+		},
+		Patterns: []*regexp.Regexp{emailPattern, bearerPattern, dsnPattern},
+	}
+}
+
+// Redact returns a copy of the whole chain with sensitive data replaced according to p:
+// meta values of matching keys and Sensitive values, and pattern matches in causes, descriptions, hints and string meta.
+// Causes that are not *Error values are replaced by their redacted text if it differs.
+//
+// The error itself is not modified. If p is nil, e is returned as is.
+func (e *Error) Redact(p *Policy) *Error {
+	if p == nil || e == nil {
+		return e
+	}
+
+	res := *e
+	res.Core.Desc = p.text(e.Core.Desc)
+	res.Core.Hint = p.text(e.Core.Hint)
+
+	if e.meta != nil {
+		res.meta = make(map[string]any, len(e.meta))
+		for k, v := range e.meta {
+			res.meta[k] = p.value(k, v)
+		}
+	}
+
+	switch cause := e.Core.Cause.(type) {
+	case nil:
+	case *Error:
+		res.Core.Cause = cause.Redact(p)
+	default:
+		if text := p.text(cause.Error()); text != cause.Error() {
+			res.Core.Cause = &textError{msg: text}
+		}
+	}
+
+	if len(e.children) != 0 {
+		res.children = make([]FieldError, len(e.children))
+		errs := make([]error, len(e.children))
+		for i, c := range e.children {
+			res.children[i] = FieldError{Field: c.Field, Err: c.Err.Redact(p)}
+			errs[i] = res.children[i].Err
+		}
+		res.Core.Cause = errors.Join(errs...)
+	}
+
+	res.underlying = e.underlying.Redact(p)
+	return &res
+}
+
+// String returns Mask.
+func (s Sensitive) String() string {
+	return Mask
+}
+
+// GoString returns Mask, so %#v does not print the value either.
+func (s Sensitive) GoString() string {
+	return Mask
+}
+
+// MarshalJSON encodes Mask.
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Mask)
+}
+
+// MarshalText encodes Mask, e.g. for XML.
+func (s Sensitive) MarshalText() ([]byte, error) {
+	return []byte(Mask), nil
+}
+
+// LogValue implements slog.LogValuer.
+func (s Sensitive) LogValue() slog.Value {
+	return slog.StringValue(Mask)
+}
+
+// value returns the redacted meta value of key.
+func (p *Policy) value(key string, v any) any {
+	if s, ok := v.(Sensitive); ok {
+		return p.replace(s.Value)
+	}
+	if p.sensitiveKey(key) {
+		return p.replace(v)
+	}
+	switch v := v.(type) {
+	case string:
+		return p.text(v)
+	case map[string]any:
+		res := maps.Clone(v)
+		for k, val := range res {
+			res[k] = p.value(k, val)
+		}
+		return res
+	}
+	return v
+}
+
+// sensitiveKey reports whether key ends with the segments of one of p.Keys.
+func (p *Policy) sensitiveKey(key string) bool {
+	segs := segments(key)
+	for _, k := range p.Keys {
+		if want := segments(k); len(want) != 0 && len(want) <= len(segs) && slices.Equal(segs[len(segs)-len(want):], want) {
+			return true
+		}
+	}
+	return false
+}
+
+// segments splits key into lowercase segments at '_', '-', '.' and camelCase boundaries.
+func segments(key string) []string {
+	var (
+		res   []string
+		start int
+		prev  rune
+	)
+	for i, r := range key {
+		switch {
+		case r == '_' || r == '-' || r == '.':
+			if i > start {
+				res = append(res, strings.ToLower(key[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			res = append(res, strings.ToLower(key[start:i]))
+			start = i
+		}
+		prev = r
+	}
+	if len(key) > start {
+		res = append(res, strings.ToLower(key[start:]))
+	}
+	return res
+}
+
+// text redacts pattern matches in s.
+func (p *Policy) text(s string) string {
+	for _, re := range p.Patterns {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			return p.replace(match).(string)
+		})
+	}
+	return s
+}
+
+// replace returns the replacement of a sensitive value.
+func (p *Policy) replace(v any) any {
+	if p.Hash {
+		sum := sha256.Sum256([]byte(p.Salt + fmt.Sprint(v)))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	}
+	if p.Mask != "" {
+		return p.Mask
+	}
+	return Mask
+}
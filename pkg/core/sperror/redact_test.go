@@ -0,0 +1,189 @@
+package sperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func ExampleError_Redact() {
+	err := New(Sample{
+		Desc:  "login of john@example.com failed",
+		Level: levels.LevelError,
+		Meta:  map[string]any{"password": "hunter2", "attempt": 3},
+	})
+
+	red := err.Redact(DefaultPolicy())
+	fmt.Println(red.Desc(), red.Meta("password"), red.Meta("attempt"))
+	// Output: login of [REDACTED] failed [REDACTED] 3
+}
+
+func TestError_Redact(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    *Policy
+		err       *Error
+		wantDesc  string
+		wantMeta  map[string]any
+		wantCause string
+	}{
+		{
+			name:     "nil policy",
+			err:      New(Sample{Desc: "mail a@b.io", Meta: map[string]any{"token": "t"}}),
+			wantDesc: "mail a@b.io",
+			wantMeta: map[string]any{"token": "t"},
+		},
+		{
+			name:   "keys",
+			policy: DefaultPolicy(),
+			err: New(Sample{Desc: "plain", Meta: map[string]any{
+				"Access-Token": "abc",
+				"user_id":      7,
+				"headers":      map[string]any{"Authorization": "Basic x", "Accept": "*/*"},
+			}}),
+			wantDesc: "plain",
+			wantMeta: map[string]any{
+				"Access-Token": Mask,
+				"user_id":      7,
+				"headers":      map[string]any{"Authorization": Mask, "Accept": "*/*"},
+			},
+		},
+		{
+			name:      "patterns",
+			policy:    DefaultPolicy(),
+			err:       Internal(errors.New("dial postgres://admin:pwd@db:5432 failed"), "sent Bearer abc.def to a@b.io", "retry"),
+			wantDesc:  "sent [REDACTED] to [REDACTED]",
+			wantMeta:  map[string]any{},
+			wantCause: "dial postgres://[REDACTED]db:5432 failed",
+		},
+		{
+			name:     "sensitive",
+			policy:   &Policy{Mask: "***"},
+			err:      New(Sample{Desc: "card", Meta: map[string]any{"card": Sensitive{Value: "4242"}}}),
+			wantDesc: "card",
+			wantMeta: map[string]any{"card": "***"},
+		},
+		{
+			name:     "custom pattern",
+			policy:   &Policy{Patterns: []*regexp.Regexp{regexp.MustCompile(`\d{4}-\d{4}`)}},
+			err:      New(Sample{Desc: "card 1234-5678", Meta: map[string]any{"note": "paid by 1111-2222"}}),
+			wantDesc: "card [REDACTED]",
+			wantMeta: map[string]any{"note": "paid by [REDACTED]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := fmt.Sprint(tt.err.AllMeta())
+			got := tt.err.Redact(tt.policy)
+
+			if got.Desc() != tt.wantDesc {
+				t.Errorf("Redact() desc = %v, want %v", got.Desc(), tt.wantDesc)
+			}
+			if !reflect.DeepEqual(got.AllMeta(), tt.wantMeta) {
+				t.Errorf("Redact() meta = %v, want %v", got.AllMeta(), tt.wantMeta)
+			}
+			if tt.wantCause != "" && got.Caused().Error() != tt.wantCause {
+				t.Errorf("Redact() cause = %v, want %v", got.Caused(), tt.wantCause)
+			}
+			if fmt.Sprint(tt.err.AllMeta()) != before {
+				t.Errorf("Redact() modified the error: %v", tt.err.AllMeta())
+			}
+		})
+	}
+}
+
+func TestPolicy_sensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		keys []string
+		want bool
+	}{
+		{key: "access_token", want: true},
+		{key: "X-Access-Token", want: true},
+		{key: "accessToken", want: true},
+		{key: "db.password", want: true},
+		{key: "sessionId", want: true},
+		{key: "X-API-Key", want: true},
+		{key: "tokens_used", want: false},
+		{key: "token_type", want: false},
+		{key: "session_count", want: false},
+		{key: "passwords_reset", want: false},
+		{key: "author", keys: []string{"auth"}, want: false},
+		{key: "user_auth", keys: []string{"auth"}, want: true},
+		{key: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			p := DefaultPolicy()
+			if tt.keys != nil {
+				p.Keys = tt.keys
+			}
+			if got := p.sensitiveKey(tt.key); got != tt.want {
+				t.Errorf("sensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_RedactChain(t *testing.T) {
+	inner := New(Sample{Desc: "inner", Level: levels.LevelDebug, Meta: map[string]any{"secret": "s1"}})
+	err := WrapNew(inner, Sample{Desc: "outer", Level: levels.LevelError, Meta: map[string]any{MetaRequestID: "req-1"}})
+	group := new(Group).Add("email", Internal(nil, "a@b.io is taken", "use another")).Err()
+
+	red := err.Redact(DefaultPolicy())
+	if v := red.Spin(levels.LevelDebug).Meta("secret"); v != Mask {
+		t.Errorf("inner layer meta = %v, want %v", v, Mask)
+	}
+	if v := inner.Meta("secret"); v != "s1" {
+		t.Errorf("inner layer was modified: %v", v)
+	}
+	if red.Depth() != err.Depth() || red.ContextMeta()[MetaRequestID] != "req-1" {
+		t.Errorf("Redact() chain = %v", red)
+	}
+
+	rg := group.Redact(DefaultPolicy())
+	if desc := rg.Children()[0].Err.Desc(); desc != "[REDACTED] is taken" {
+		t.Errorf("child desc = %v", desc)
+	}
+	if strings.Contains(rg.Error(), "a@b.io") || strings.Contains(rg.Caused().Error(), "a@b.io") {
+		t.Errorf("Redact() left the email in %v", rg)
+	}
+}
+
+func TestPolicy_Hash(t *testing.T) {
+	p := &Policy{Keys: []string{"email"}, Hash: true, Salt: "s"}
+	a := New(Sample{Meta: map[string]any{"email": "a@b.io"}}).Redact(p).Meta("email")
+	b := New(Sample{Meta: map[string]any{"email": "a@b.io"}}).Redact(p).Meta("email")
+	c := New(Sample{Meta: map[string]any{"email": "c@d.io"}}).Redact(p).Meta("email")
+	salted := New(Sample{Meta: map[string]any{"email": "a@b.io"}}).Redact(&Policy{Keys: p.Keys, Hash: true}).Meta("email")
+
+	if s, _ := a.(string); !strings.HasPrefix(s, "sha256:") || a != b {
+		t.Errorf("hashes = %v, %v, want equal sha256 hashes", a, b)
+	}
+	if a == c || a == salted {
+		t.Errorf("hashes of different values or salts are equal: %v", a)
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	s := Sensitive{Value: "hunter2"}
+	err := New(Sample{Desc: "x", Meta: map[string]any{"pin": s}})
+
+	data, _ := json.Marshal(err)
+	var b strings.Builder
+	slog.New(slog.NewTextHandler(&b, nil)).Info("x", "pin", s)
+
+	for _, out := range []string{fmt.Sprint(s), fmt.Sprintf("%#v", s), fmt.Sprintf("%+v", err), string(data), b.String()} {
+		if strings.Contains(out, "hunter2") {
+			t.Errorf("Sensitive value leaked: %s", out)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package sperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// MetaAttempts is the meta key of the []Attempt record of an error returned by Retry.
+const MetaAttempts = "attempts"
+
+type (
+	// retryInfo is the retry classification of a single layer.
+	retryInfo struct {
+		set       bool // the layer is classified explicitly, so the HTTP code is not used to infer it
+		retryable bool
+		temporary bool
+		timeout   bool
+		after     time.Duration
+	}
+
+	// RetryPolicy configures Retry. Zero fields take the defaults of DefaultRetryPolicy, except Jitter.
+	RetryPolicy struct {
+		MaxAttempts int           // attempts including the first one
+		Base        time.Duration // delay after the first failed attempt
+		Max         time.Duration // upper bound of the backoff delay
+		Multiplier  float64       // growth of the delay after every attempt
+		Jitter      float64       // random spread of the delay, 0.2 makes it vary within ±20%; 0 disables jitter, 1 is the maximum
+	}
+
+	// RetryClass is the explicit retry classification of a single layer, see Error.RetryClass.
+	RetryClass struct {
+		Retryable  bool
+		Temporary  bool
+		Timeout    bool
+		RetryAfter time.Duration
+	}
+
+	// Attempt is the record of a failed attempt of Retry.
+	Attempt struct {
+		Number int           `json:"number"`
+		Err    string        `json:"error"`
+		Delay  time.Duration `json:"delay"` // wait before the next attempt, 0 for the last one
+	}
+)
+
+// DefaultRetryPolicy returns the policy of up to 3 attempts with delays starting at 100ms,
+// doubling up to 10s, with ±20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Base:        100 * time.Millisecond,
+		Max:         10 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// SetRetryable marks the error as worth retrying or not. An explicit classification of any kind
+// turns off the inference from the HTTP code, see Retryable.
+func (e *Error) SetRetryable(retryable bool) *Error {
+	e = e.writable()
+	e.retry.set = true
+	e.retry.retryable = retryable
+	return e
+}
+
+// SetTemporary marks the error as a temporary condition, e.g. an overloaded dependency.
+func (e *Error) SetTemporary(temporary bool) *Error {
+	e = e.writable()
+	e.retry.set = true
+	e.retry.temporary = temporary
+	return e
+}
+
+// SetTimeout marks the error as caused by a timeout.
+func (e *Error) SetTimeout(timeout bool) *Error {
+	e = e.writable()
+	e.retry.set = true
+	e.retry.timeout = timeout
+	return e
+}
+
+// SetRetryAfter sets how long to wait before retrying, e.g. taken from the Retry-After header.
+// A positive delay marks the error as retryable as well.
+func (e *Error) SetRetryAfter(d time.Duration) *Error {
+	e = e.writable()
+	e.retry.set = true
+	e.retry.after = d
+	if d > 0 {
+		e.retry.retryable = true
+	}
+	return e
+}
+
+// RetryClass returns the classification set on the layer e itself, ignoring the rest of the chain.
+// ok is false if the layer is not classified explicitly. Together with SetRetryClass it lets codecs
+// carry the classification layer by layer; use Retryable and the other getters to decide on retries.
+func (e *Error) RetryClass() (c RetryClass, ok bool) {
+	r := e.retry
+	return RetryClass{Retryable: r.retryable, Temporary: r.temporary, Timeout: r.timeout, RetryAfter: r.after}, r.set
+}
+
+// SetRetryClass classifies the layer explicitly with exactly c, see RetryClass.
+func (e *Error) SetRetryClass(c RetryClass) *Error {
+	e = e.writable()
+	e.retry = retryInfo{set: true, retryable: c.Retryable, temporary: c.Temporary, timeout: c.Timeout, after: c.RetryAfter}
+	return e
+}
+
+// Retryable reports whether the operation that failed with e is worth retrying.
+//
+// The outermost explicitly classified layer of the Wrap chain decides, see SetRetryable and Sample.Retryable;
+// *Error causes are checked after the layers. Errors of Ensure are classified from the original error:
+// context.DeadlineExceeded and net.Error timeouts or temporary errors are retryable, context.Canceled is not.
+// If nothing in the chain is classified, errors with 429 Too Many Requests or 503 Service Unavailable codes are retryable.
+func (e *Error) Retryable() bool {
+	if info, ok := e.class(); ok {
+		return info.retryable
+	}
+	return e.retryCode()
+}
+
+// IsTemporary reports whether e is a temporary condition, see Retryable for the rules.
+// The methods are not named Temporary and Timeout on purpose: *Error is not a net.Error,
+// so errors.As with a net.Error target finds only real network errors in a chain.
+func (e *Error) IsTemporary() bool {
+	if info, ok := e.class(); ok {
+		return info.temporary
+	}
+	return e.retryCode()
+}
+
+// IsTimeout reports whether e is caused by a timeout, see Retryable for the rules.
+func (e *Error) IsTimeout() bool {
+	info, _ := e.class()
+	return info.timeout
+}
+
+// RetryAfter returns how long to wait before retrying, 0 if the server did not say, see Retryable for the rules.
+func (e *Error) RetryAfter() time.Duration {
+	info, _ := e.class()
+	return info.after
+}
+
+// class returns the classification of the outermost classified layer of the chain or of its *Error causes.
+func (e *Error) class() (retryInfo, bool) {
+	for _, layer := range e.Layers() {
+		if layer.retry.set {
+			return layer.retry, true
+		}
+	}
+	for cause := range e.Causes() {
+		if sperr, ok := cause.(*Error); ok {
+			if info, ok := sperr.class(); ok {
+				return info, true
+			}
+		}
+	}
+	return retryInfo{}, false
+}
+
+// retryCode reports whether any layer has an HTTP code that is retryable by definition.
+func (e *Error) retryCode() bool {
+	for _, layer := range e.Layers() {
+		if layer.User.HttpCode == http.StatusTooManyRequests || layer.User.HttpCode == http.StatusServiceUnavailable {
+			return true
+		}
+	}
+	return false
+}
+
+// inferRetry classifies an error that is not *Error, see Ensure.
+func inferRetry(err error) retryInfo {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return retryInfo{set: true, retryable: true, temporary: true, timeout: true}
+	case errors.Is(err, context.Canceled):
+		return retryInfo{set: true}
+	}
+
+	if sperr, ok := find[*Error](err); ok {
+		// *Error wrapped by a foreign error, e.g. with fmt.Errorf
+		return retryInfo{
+			set:       true,
+			retryable: sperr.Retryable(),
+			temporary: sperr.IsTemporary(),
+			timeout:   sperr.IsTimeout(),
+			after:     sperr.RetryAfter(),
+		}
+	}
+	ne, ok := find[net.Error](err)
+	if !ok {
+		return retryInfo{}
+	}
+	info := retryInfo{set: true, timeout: ne.Timeout()}
+	if tmp, ok := ne.(interface{ Temporary() bool }); ok {
+		info.temporary = tmp.Temporary()
+	}
+	info.retryable = info.timeout || info.temporary
+	return info
+}
+
+// find finds the first T in the tree of err like errors.As, but without allocating.
+func find[T error](err error) (T, bool) {
+	for err != nil {
+		if t, ok := err.(T); ok {
+			return t, true
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, member := range x.Unwrap() {
+				if t, ok := find[T](member); ok {
+					return t, true
+				}
+			}
+			return *new(T), false
+		default:
+			return *new(T), false
+		}
+	}
+	return *new(T), false
+}
+
+// Retry calls fn until it succeeds, returns an error that is not Retryable, the context is done
+// or p.MaxAttempts attempts are made.
+//
+// Delays grow exponentially from p.Base by p.Multiplier up to p.Max and are spread by p.Jitter.
+// If the error has RetryAfter, the delay is at least that long.
+//
+// If fn fails more than once, the last error is wrapped into a layer with the same messages, code and level,
+// whose MetaAttempts meta holds the []Attempt record of every attempt. A single failure is returned as is.
+//
+// Usage:
+//
+//	err := sperror.Retry(ctx, sperror.DefaultRetryPolicy(), func(ctx context.Context) error {
+//		return client.Send(ctx, req)
+//	})
+func Retry(ctx context.Context, p RetryPolicy, fn func(ctx context.Context) error) error {
+	p = p.withDefaults()
+
+	var (
+		attempts []Attempt
+		last     error
+	)
+	delay := p.Base
+	for n := 1; ; n++ {
+		last = fn(ctx)
+		if last == nil {
+			return nil
+		}
+		attempts = append(attempts, Attempt{Number: n, Err: last.Error()})
+
+		e := Ensure(last)
+		if n >= p.MaxAttempts || !e.Retryable() {
+			break
+		}
+
+		wait := p.jitter(delay)
+		if after := e.RetryAfter(); after > wait {
+			wait = after
+		}
+		attempts[n-1].Delay = wait
+		delay = min(time.Duration(float64(delay)*p.Multiplier), p.Max)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return exhausted(last, attempts, fmt.Sprintf("Retry stopped after %d attempts: %v", n, ctx.Err()))
+		case <-timer.C:
+		}
+	}
+
+	if len(attempts) == 1 {
+		return last
+	}
+	return exhausted(last, attempts, fmt.Sprintf("Failed after %d attempts", len(attempts)))
+}
+
+// exhausted wraps the last error of Retry with the record of the attempts.
+// The source of the new layer is the caller of Retry.
+func exhausted(last error, attempts []Attempt, desc string) *Error {
+	e := Ensure(last)
+	res := newAt(Sample{
+		Code:     e.User.Code,
+		Messages: e.User.Messages,
+		Desc:     desc,
+		Hint:     "Check the errors of the attempts",
+		HttpCode: e.User.HttpCode,
+		Level:    e.User.Level,
+		Meta:     map[string]any{MetaAttempts: attempts},
+	}, 2)
+	res.underlying = e
+	res.remainsUnderlying = e.remainsUnderlying + 1
+	return res
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.Base <= 0 {
+		p.Base = def.Base
+	}
+	if p.Max <= 0 {
+		p.Max = def.Max
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = def.Multiplier
+	}
+	return p
+}
+
+// jitter spreads d randomly within ±p.Jitter of it. Jitter above 1 is treated as 1, so delays are never negative.
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + min(p.Jitter, 1)*(2*rand.Float64()-1)))
+}
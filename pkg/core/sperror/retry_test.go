@@ -0,0 +1,291 @@
+package sperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleRetry() {
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{Base: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return New(Sample{Desc: "busy", HttpCode: http.StatusServiceUnavailable})
+		}
+		return nil
+	})
+	fmt.Println(calls, err)
+	// Output: 3 <nil>
+}
+
+func TestError_Retryable(t *testing.T) {
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}
+
+	tests := []struct {
+		name          string
+		err           *Error
+		wantRetryable bool
+		wantTemporary bool
+		wantTimeout   bool
+		wantAfter     time.Duration
+	}{
+		{
+			name: "plain",
+			err:  New(Sample{Desc: "plain", HttpCode: http.StatusInternalServerError}),
+		},
+		{
+			name:          "too many requests",
+			err:           New(Sample{Desc: "slow down", HttpCode: http.StatusTooManyRequests}),
+			wantRetryable: true,
+			wantTemporary: true,
+		},
+		{
+			name: "explicit over code",
+			err:  New(Sample{Desc: "maintenance", HttpCode: http.StatusServiceUnavailable}).SetRetryable(false),
+		},
+		{
+			name:          "sample",
+			err:           New(Sample{Desc: "limited", RetryAfter: time.Minute}),
+			wantRetryable: true,
+			wantAfter:     time.Minute,
+		},
+		{
+			name:          "deadline",
+			err:           Ensure(fmt.Errorf("query: %w", context.DeadlineExceeded)),
+			wantRetryable: true,
+			wantTemporary: true,
+			wantTimeout:   true,
+		},
+		{
+			name: "canceled",
+			err:  Ensure(context.Canceled),
+		},
+		{
+			name:          "net timeout",
+			err:           Ensure(fmt.Errorf("call users: %w", timeout)),
+			wantRetryable: true,
+			wantTemporary: true,
+			wantTimeout:   true,
+		},
+		{
+			name:          "wrapped sperror",
+			err:           Ensure(fmt.Errorf("call: %w", New(Sample{HttpCode: http.StatusTooManyRequests}))),
+			wantRetryable: true,
+			wantTemporary: true,
+		},
+		{
+			name:          "inner layer",
+			err:           WrapNew(New(Sample{Desc: "db", Timeout: true, Retryable: true}), Sample{Desc: "service"}),
+			wantRetryable: true,
+			wantTimeout:   true,
+		},
+		{
+			name: "outer layer wins",
+			err: WrapNew(New(Sample{Desc: "db", Retryable: true}), Sample{Desc: "service"}).
+				SetRetryable(false),
+		},
+		{
+			name:          "cause",
+			err:           Internal(New(Sample{Desc: "db", Temporary: true}), "query failed", "check db"),
+			wantTemporary: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Retryable(); got != tt.wantRetryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := tt.err.IsTemporary(); got != tt.wantTemporary {
+				t.Errorf("IsTemporary() = %v, want %v", got, tt.wantTemporary)
+			}
+			if got := tt.err.IsTimeout(); got != tt.wantTimeout {
+				t.Errorf("IsTimeout() = %v, want %v", got, tt.wantTimeout)
+			}
+			if got := tt.err.RetryAfter(); got != tt.wantAfter {
+				t.Errorf("RetryAfter() = %v, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+
+	var ne net.Error
+	if errors.As(error(New(Sample{Timeout: true})), &ne) {
+		t.Errorf("*Error is a net.Error")
+	}
+	if !errors.As(error(Ensure(fmt.Errorf("call users: %w", timeout))), &ne) || ne != timeout {
+		t.Errorf("errors.As() = %v, want the network error of the chain", ne)
+	}
+}
+
+func TestError_RetryJSON(t *testing.T) {
+	err := New(Sample{Desc: "limited", Retryable: true, Temporary: true, RetryAfter: 1500 * time.Millisecond})
+
+	data, e := json.Marshal(err)
+	if e != nil {
+		t.Fatal(e)
+	}
+	got := &Error{}
+	if e = json.Unmarshal(data, got); e != nil {
+		t.Fatal(e)
+	}
+	if got.retry != err.retry {
+		t.Errorf("round trip retry = %+v, want %+v", got.retry, err.retry)
+	}
+}
+
+func TestError_RetryClass(t *testing.T) {
+	if _, ok := New(Sample{HttpCode: http.StatusServiceUnavailable}).RetryClass(); ok {
+		t.Errorf("RetryClass() of an inferred classification ok = true")
+	}
+
+	want := RetryClass{Temporary: true, RetryAfter: time.Second}
+	err := New(Sample{Retryable: true}).Freeze()
+	got := err.SetRetryClass(want)
+	if c, ok := got.RetryClass(); !ok || c != want {
+		t.Errorf("RetryClass() = %+v, %v, want %+v, true", c, ok, want)
+	}
+	if c, _ := err.RetryClass(); !c.Retryable {
+		t.Errorf("SetRetryClass() modified a frozen error")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	busy := New(Sample{Desc: "busy", HttpCode: http.StatusServiceUnavailable, Level: levels.LevelUser})
+	fatal := New(Sample{Desc: "fatal", HttpCode: http.StatusBadRequest})
+	policy := RetryPolicy{MaxAttempts: 3, Base: time.Millisecond, Jitter: 0.5}
+
+	tests := []struct {
+		name         string
+		ctx          func() context.Context
+		errs         []error
+		wantCalls    int
+		wantAttempts []string
+		wantErr      error
+	}{
+		{
+			name:      "success",
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "success after retries",
+			errs:      []error{busy, busy, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "not retryable",
+			errs:      []error{fatal},
+			wantCalls: 1,
+			wantErr:   fatal,
+		},
+		{
+			name:         "stops on not retryable",
+			errs:         []error{busy, fatal},
+			wantCalls:    2,
+			wantAttempts: []string{"busy", "fatal"},
+			wantErr:      fatal,
+		},
+		{
+			name:         "exhausted",
+			errs:         []error{busy, busy, busy, nil},
+			wantCalls:    3,
+			wantAttempts: []string{"busy", "busy", "busy"},
+			wantErr:      busy,
+		},
+		{
+			name: "canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			errs:         []error{busy.Copy().SetRetryAfter(time.Hour), nil},
+			wantCalls:    1,
+			wantAttempts: []string{"busy"},
+			wantErr:      busy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+
+			calls := 0
+			err := Retry(ctx, policy, func(context.Context) error {
+				calls++
+				return tt.errs[calls-1]
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("Retry() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Retry() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Retry() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantAttempts == nil {
+				if err != tt.wantErr {
+					t.Errorf("Retry() error = %v, want it as is", err)
+				}
+				return
+			}
+
+			e := Ensure(err)
+			attempts, _ := e.Meta(MetaAttempts).([]Attempt)
+			var got []string
+			for i, a := range attempts {
+				if a.Number != i+1 {
+					t.Errorf("attempt %d number = %d", i, a.Number)
+				}
+				got = append(got, a.Err)
+			}
+			if !reflect.DeepEqual(got, tt.wantAttempts) {
+				t.Errorf("Retry() attempts = %v, want %v", got, tt.wantAttempts)
+			}
+			if e.Code() != Ensure(tt.wantErr).Code() || e.Level() != Ensure(tt.wantErr).Level() {
+				t.Errorf("Retry() error code = %d, level = %d", e.Code(), e.Level())
+			}
+			if !strings.Contains(e.Source(), "retry_test.go") {
+				t.Errorf("Retry() error source = %v, want the caller of Retry", e.Source())
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Delays(t *testing.T) {
+	p := RetryPolicy{Base: time.Second, Max: 3 * time.Second, Multiplier: 2, Jitter: 0.2}.withDefaults()
+	if p.MaxAttempts != 3 {
+		t.Errorf("withDefaults() MaxAttempts = %d, want 3", p.MaxAttempts)
+	}
+	for range 100 {
+		if d := p.jitter(time.Second); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("jitter() = %v, want within ±20%%", d)
+		}
+	}
+	for range 100 {
+		if d := (RetryPolicy{Jitter: 3}).jitter(time.Second); d < 0 || d > 2*time.Second {
+			t.Fatalf("jitter() above 1 = %v, want within ±100%%", d)
+		}
+	}
+	if d := (RetryPolicy{}).jitter(time.Second); d != time.Second {
+		t.Errorf("jitter() without Jitter = %v, want %v", d, time.Second)
+	}
+}
@@ -2,17 +2,29 @@ package sperror
 
 import (
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"time"
 )
 
 // Sample represents a structured template for error information with localization, details, and metadata.
 type Sample struct {
+	Code     string            // stable machine-readable code, e.g. "billing.card_declined"
 	Messages map[string]string // localized message
 	Desc     string            // detailed description
 	Hint     string            // how to resolve
 
+	// Plain marks messages, the description and the hint as plain text that is never rendered as a template,
+	// e.g. errors of other packages or remote input. They are kept and exported as they are.
+	Plain bool
+
 	HttpCode int          // HTTP status
 	Level    levels.Level // error level
 
 	Cause error          // nested error
 	Meta  map[string]any // arbitrary fields (user_id, trace_id, etc.)
+
+	// Retry classification, see Error.Retryable. Setting any of these turns off the inference from HttpCode.
+	Retryable  bool          // the operation is worth retrying
+	Temporary  bool          // a temporary condition, e.g. an overloaded dependency
+	Timeout    bool          // caused by a timeout
+	RetryAfter time.Duration // how long to wait before retrying, a positive delay implies Retryable
 }
@@ -4,23 +4,6 @@ import (
 	"github.com/s4bb4t/lighthouse/pkg/core/levels"
 )
 
-// Pop extracts and returns the current error from the error chain.
-// If the current object is nil or there are no more underlying errors (remainsUnderlying == -1),
-// returns nil.
-func (e *Error) pop() *Error {
-	if e == nil || e.remainsUnderlying == -1 {
-		return nil
-	}
-	result := *e
-	result.underlying = nil
-	if e.underlying != nil {
-		*e = *e.underlying
-	} else {
-		e.remainsUnderlying--
-	}
-	return &result
-}
-
 // Spin returns the most relevant *Error instance from the error chain that matches the provided severity level.
 //
 // This method traverses the error chain (created via Wrap) and returns the last *Error whose Level is
@@ -41,6 +24,7 @@ func (e *Error) pop() *Error {
 //	}
 //
 // If no error matches the level, Spin returns a generic internal error.
+// A multi-error passed through Ensure is a single layer with the highest level of its members.
 //
 // Recommended practices:
 // - Always wrap each layer’s error with Wrap()
@@ -51,26 +35,22 @@ func (e *Error) Spin(lvl levels.Level) *Error {
 		return nil
 	}
 
-	cp := &Error{}
-	*cp = *e
-	cur := cp.pop()
-	if cur == nil {
-		return nil
-	}
-
-	if cur.User.Level > lvl {
-		return New(Sample{
+	if e.User.Level > lvl {
+		return newAt(Sample{
 			Messages: map[string]string{
 				En: "No error found for level",
 			},
 			Desc: "Level provided is higher than the level of the error",
 			Hint: "Please, check your code and provide a valid error level",
-		}).path(1)
+		}, 1)
 	}
 
-	last := cur
-	for cur != nil && cur.User.Level <= lvl {
-		last, cur = cur, cp.pop()
+	last := e
+	for _, layer := range e.Layers() {
+		if layer.User.Level > lvl {
+			break
+		}
+		last = layer
 	}
-	return last
+	return last.detach()
 }
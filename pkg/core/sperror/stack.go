@@ -0,0 +1,160 @@
+package sperror
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+)
+
+// maxStackDepth limits the number of program counters captured for a single error.
+const maxStackDepth = 64
+
+type (
+	// Frame is a single resolved call of a stack trace.
+	Frame struct {
+		Function string `json:"function"`
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+	}
+
+	// StackTrace is a list of frames ordered from the innermost call outwards.
+	StackTrace []Frame
+
+	// stack holds captured program counters and resolves them into frames on first use.
+	stack struct {
+		pcs    []uintptr
+		once   sync.Once
+		frames StackTrace
+	}
+)
+
+var stackCfg struct {
+	enabled atomic.Bool
+	level   atomic.Uint32
+}
+
+// sources caches "file:line" sources by program counter, so every call site is resolved and formatted once.
+var sources = struct {
+	sync.RWMutex
+	m map[uintptr]string
+}{m: make(map[uintptr]string)}
+
+// EnableStack turns stack trace capturing on or off for every error created afterwards.
+// Capturing is disabled by default, since it costs a runtime.Callers call per error.
+func EnableStack(on bool) {
+	stackCfg.enabled.Store(on)
+}
+
+// SetStackLevel limits stack trace capturing to errors with level lvl and above.
+// For example, SetStackLevel(levels.LevelError) skips capturing for LevelUser and LevelInfo errors.
+// It takes effect only while capturing is enabled with EnableStack.
+func SetStackLevel(lvl levels.Level) {
+	stackCfg.level.Store(uint32(lvl))
+}
+
+// String returns the frame in "function\n\tfile:line" form.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// String returns all frames, one frame per two lines.
+func (s StackTrace) String() string {
+	var b strings.Builder
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
+
+// source returns the "file:line" location of the caller, skipping lvl frames above it.
+func source(lvl int) (string, bool) {
+	var pc [1]uintptr
+	if runtime.Callers(lvl+2, pc[:]) == 0 {
+		return "", false
+	}
+
+	sources.RLock()
+	src, ok := sources.m[pc[0]]
+	sources.RUnlock()
+	if ok {
+		return src, true
+	}
+
+	// a separate slice keeps pc on the stack on the hot path
+	frame, _ := runtime.CallersFrames([]uintptr{pc[0]}).Next()
+	src = frame.File + ":" + strconv.Itoa(frame.Line)
+
+	sources.Lock()
+	sources.m[pc[0]] = src
+	sources.Unlock()
+	return src, true
+}
+
+// callers captures the stack of the caller, skipping lvl frames above it.
+func callers(lvl int) *stack {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(lvl+2, pcs)
+	return &stack{pcs: pcs[:n]}
+}
+
+// trace resolves captured program counters into frames.
+func (s *stack) trace() StackTrace {
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		if len(s.pcs) == 0 {
+			return
+		}
+		frames := runtime.CallersFrames(s.pcs)
+		for {
+			f, more := frames.Next()
+			s.frames = append(s.frames, Frame{Function: f.Function, File: f.File, Line: f.Line})
+			if !more {
+				break
+			}
+		}
+	})
+	return s.frames
+}
+
+// captureStack is an internal method that records the caller's stack if capturing is enabled
+// for the error's level.
+func (e *Error) captureStack(lvl int) *Error {
+	if stackCfg.enabled.Load() && uint32(e.User.Level) >= stackCfg.level.Load() {
+		e.stack = callers(lvl + 1)
+	}
+	return e
+}
+
+// SetStack records the caller's stack trace regardless of the global and per-level switches.
+func (e *Error) SetStack() *Error {
+	e = e.writable()
+	e.stack = callers(1)
+	return e
+}
+
+// SetStackTrace sets a stack trace resolved elsewhere, e.g. decoded by another codec. Nil removes the stack trace.
+func (e *Error) SetStackTrace(st StackTrace) *Error {
+	e = e.writable()
+	e.stack = nil
+	if len(st) != 0 {
+		e.stack = &stack{frames: slices.Clone(st)}
+	}
+	return e
+}
+
+// StackTrace returns the stack trace captured when the error was created.
+// It returns nil if capturing was disabled for the error.
+func (e *Error) StackTrace() StackTrace {
+	return e.stack.trace()
+}
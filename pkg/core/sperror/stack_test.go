@@ -0,0 +1,149 @@
+package sperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"strings"
+	"testing"
+)
+
+func TestError_StackTrace(t *testing.T) {
+	defer EnableStack(false)
+	defer SetStackLevel(levels.LevelNoop)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		level   levels.Level
+		err     func() *Error
+		want    bool
+	}{
+		{
+			name: "disabled",
+			err:  DB,
+			want: false,
+		},
+		{
+			name:    "enabled",
+			enabled: true,
+			err:     DB,
+			want:    true,
+		},
+		{
+			name:    "below level",
+			enabled: true,
+			level:   levels.LevelError,
+			err: func() *Error {
+				return BadRequest("desc", "hint")
+			},
+			want: false,
+		},
+		{
+			name:    "above level",
+			enabled: true,
+			level:   levels.LevelError,
+			err:     App,
+			want:    true,
+		},
+		{
+			name:    "ensure",
+			enabled: true,
+			err: func() *Error {
+				return Ensure(errStack)
+			},
+			want: true,
+		},
+		{
+			name: "forced",
+			err: func() *Error {
+				return NewSpErr().SetStack()
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			EnableStack(tt.enabled)
+			SetStackLevel(tt.level)
+
+			st := tt.err().StackTrace()
+			if (st != nil) != tt.want {
+				t.Fatalf("StackTrace() = %v, want captured %v", st, tt.want)
+			}
+			if st == nil {
+				return
+			}
+			if !strings.Contains(st[0].Function, "TestError_StackTrace") && !strings.HasSuffix(st[0].Function, "sperror.App") && !strings.HasSuffix(st[0].Function, "sperror.DB") {
+				t.Errorf("StackTrace()[0] = %v, want caller frame", st[0])
+			}
+		})
+	}
+}
+
+func TestError_StackTraceJSON(t *testing.T) {
+	err := DB().SetStack()
+
+	b, e := json.Marshal(err)
+	if e != nil {
+		t.Fatal(e)
+	}
+	got := &Error{}
+	if e = json.Unmarshal(b, got); e != nil {
+		t.Fatal(e)
+	}
+	if got.StackTrace().String() != err.StackTrace().String() {
+		t.Errorf("StackTrace() = %v, want %v", got.StackTrace(), err.StackTrace())
+	}
+}
+
+func TestConstructors_CallerStack(t *testing.T) {
+	EnableStack(true)
+	defer EnableStack(false)
+
+	var g Group
+	g.Add("email", New(Sample{}))
+	tests := map[string]*Error{
+		"New":       New(Sample{}),
+		"WrapNew":   WrapNew(New(Sample{}), Sample{}),
+		"NewCtx":    NewCtx(context.Background(), Sample{}),
+		"Ensure":    Ensure(errStack),
+		"Internal":  Internal(nil, "failed", "retry"),
+		"NotFound":  NotFound("missing", "check"),
+		"Builder":   Builder(),
+		"Group":     g.Err(),
+		"HelperNew": helperNew(),
+	}
+
+	for name, err := range tests {
+		t.Run(name, func(t *testing.T) {
+			if !strings.Contains(err.Source(), "stack_test.go") {
+				t.Errorf("Source() = %v, want the caller", err.Source())
+			}
+			if st := err.StackTrace(); len(st) == 0 || !strings.HasSuffix(st[0].Function, "TestConstructors_CallerStack") {
+				t.Errorf("StackTrace() = %v, want the caller first", st)
+			}
+		})
+	}
+}
+
+func TestError_SetStackTrace(t *testing.T) {
+	st := StackTrace{{Function: "main.main", File: "main.go", Line: 7}}
+	err := New(Sample{}).SetStackTrace(st)
+	st[0].Line = 8
+	if got := err.StackTrace(); len(got) != 1 || got[0].Line != 7 {
+		t.Errorf("StackTrace() = %v, want the frame of main.go:7", got)
+	}
+	if got := err.SetStackTrace(nil).StackTrace(); got != nil {
+		t.Errorf("StackTrace() after SetStackTrace(nil) = %v, want nil", got)
+	}
+}
+
+var errStack = errors.New("plain")
+
+// helperNew is a constructor like the ones generated by lighthouse-gen.
+func helperNew() *Error {
+	return HelperNew(Sample{})
+}
@@ -0,0 +1,367 @@
+package sperror
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Messages, descriptions and hints are templates filled from the error's meta when they are rendered:
+//   - {name} is the meta value of "name"
+//   - {count, plural, =0 {no files} one {# file} other {# files}} is the CLDR plural form for count, # is the number
+//   - {{ and }} are literal braces, braces around anything but a placeholder are literal as well
+//
+// Plural selectors are exact values (=0, =1, ...) and CLDR categories: zero, one, two, few, many and other.
+// If the category of the number has no branch, "other" is used.
+// A parameter missing from meta is rendered as an empty string, see Error.MissingParams.
+//
+// Texts of errors built with Sample.Plain are not templates and are rendered as they are.
+
+// Plain reports whether the messages, the description and the hint of the layer are plain text
+// rather than templates, see Sample.Plain.
+func (e *Error) Plain() bool {
+	return e.plain
+}
+
+// render renders s, a message, the description or the hint of e, in lg; plain texts are returned as is.
+func (e *Error) render(s, lg string) (string, []string) {
+	if e.plain {
+		return s, nil
+	}
+	return render(s, lg, e.meta)
+}
+
+// templates turns plain texts of e into templates, so a template can be set next to them.
+func (e *Error) templates() {
+	if !e.plain {
+		return
+	}
+	e.plain = false
+	e.Core.Desc, e.Core.Hint = EscapeTemplate(e.Core.Desc), EscapeTemplate(e.Core.Hint)
+	for lg, msg := range e.User.Messages {
+		e.User.Messages[lg] = EscapeTemplate(msg)
+	}
+}
+
+// render fills the template with meta values, plural rules of lg are used for plural forms.
+// It returns the rendered text and the names of parameters missing from meta.
+func render(tmpl, lg string, meta map[string]any) (string, []string) {
+	if !strings.ContainsAny(tmpl, "{}") {
+		return tmpl, nil
+	}
+	r := renderer{lg: lg, meta: meta}
+	var b strings.Builder
+	r.text(&b, tmpl, "")
+	return b.String(), r.missing
+}
+
+type renderer struct {
+	lg      string
+	meta    map[string]any
+	missing []string
+}
+
+// text renders s, replacing # with num when num is not empty.
+func (r *renderer) text(b *strings.Builder, s, num string) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '{' && i+1 < len(s) && s[i+1] == '{', c == '}' && i+1 < len(s) && s[i+1] == '}':
+			b.WriteByte(c)
+			i++
+		case c == '{':
+			end := closing(s, i)
+			if end < 0 {
+				b.WriteString(s[i:])
+				return
+			}
+			if isPlaceholder(s[i+1 : end]) {
+				r.placeholder(b, s[i+1:end])
+			} else {
+				// braces around anything but a placeholder are plain text, e.g. JSON in a description
+				b.WriteString(s[i : end+1])
+			}
+			i = end
+		case c == '#' && num != "":
+			b.WriteString(num)
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// placeholder renders the inside of a {...} placeholder.
+func (r *renderer) placeholder(b *strings.Builder, s string) {
+	name, rest, isPlural := strings.Cut(s, ",")
+	name = strings.TrimSpace(name)
+
+	val, ok := r.meta[name]
+	if !ok {
+		r.missing = append(r.missing, name)
+		return
+	}
+
+	_, branches, _ := strings.Cut(rest, ",")
+	if !isPlural {
+		b.WriteString(formatParam(val))
+		return
+	}
+
+	n, ok := number(val)
+	if !ok {
+		b.WriteString(formatParam(val))
+		return
+	}
+
+	num := formatParam(val)
+	brs := pluralBranches(branches)
+	for _, sel := range []string{"=" + num, PluralCategory(r.lg, n), "other"} {
+		for _, br := range brs {
+			if br.sel == sel {
+				r.text(b, br.body, num)
+				return
+			}
+		}
+	}
+}
+
+// isPlaceholder reports whether s, the inside of braces, is {name} or {name, plural, ...}.
+func isPlaceholder(s string) bool {
+	name, rest, isPlural := strings.Cut(s, ",")
+	if !isIdent(strings.TrimSpace(name)) {
+		return false
+	}
+	if !isPlural {
+		return true
+	}
+	kind, _, _ := strings.Cut(rest, ",")
+	return strings.TrimSpace(kind) == "plural"
+}
+
+// isIdent reports whether s is a parameter name: a letter or _ followed by letters, digits, _ and dots.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type branch struct {
+	sel, body string
+}
+
+// pluralBranches parses "selector {body}" pairs of a plural placeholder.
+func pluralBranches(s string) []branch {
+	var brs []branch
+	for {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			return brs
+		}
+		end := closing(s, open)
+		if end < 0 {
+			return brs
+		}
+		brs = append(brs, branch{sel: strings.TrimSpace(s[:open]), body: s[open+1 : end]})
+		s = s[end+1:]
+	}
+}
+
+// closing returns the index of the brace closing the one at open, or -1.
+func closing(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func formatParam(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// PluralCategory returns the CLDR plural category of n for the language lg:
+// "zero", "one", "two", "few", "many" or "other".
+// Region tags are reduced to their primary language, unknown languages use English rules.
+func PluralCategory(lg string, n float64) string {
+	lg, _, _ = strings.Cut(normalizeLang(lg), "-")
+
+	n = math.Abs(n)
+	integer := n == math.Trunc(n)
+	i := int64(n)
+	mod10, mod100 := i%10, i%100
+
+	switch lg {
+	case Ja, Ko, Zh, Th:
+		return "other"
+	case Fr:
+		if i == 0 || i == 1 {
+			return "one"
+		}
+	case Pt:
+		if i == 0 || i == 1 {
+			return "one"
+		}
+	case Ru, Uk:
+		switch {
+		case !integer:
+			return "other"
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case Hr:
+		switch {
+		case integer && mod10 == 1 && mod100 != 11:
+			return "one"
+		case integer && mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+	case Pl:
+		switch {
+		case !integer:
+			return "other"
+		case i == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case Cs, Cz, Sk:
+		switch {
+		case !integer:
+			return "many"
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		}
+	case Lt:
+		switch {
+		case !integer:
+			return "many"
+		case mod10 == 1 && (mod100 < 11 || mod100 > 19):
+			return "one"
+		case mod10 >= 2 && (mod100 < 11 || mod100 > 19):
+			return "few"
+		}
+	case Lv:
+		switch {
+		case integer && (mod10 == 0 || mod100 >= 11 && mod100 <= 19):
+			return "zero"
+		case integer && mod10 == 1 && mod100 != 11:
+			return "one"
+		}
+	case Ro:
+		switch {
+		case integer && i == 1:
+			return "one"
+		case !integer || i == 0 || mod100 >= 2 && mod100 <= 19:
+			return "few"
+		}
+	case Sl:
+		switch {
+		case integer && mod100 == 1:
+			return "one"
+		case integer && mod100 == 2:
+			return "two"
+		case !integer || mod100 == 3 || mod100 == 4:
+			return "few"
+		}
+	case He:
+		switch {
+		case integer && i == 1:
+			return "one"
+		case integer && i == 2:
+			return "two"
+		}
+	case Is:
+		if integer && mod10 == 1 && mod100 != 11 {
+			return "one"
+		}
+	case Ar:
+		switch {
+		case !integer:
+			return "other"
+		case i == 0:
+			return "zero"
+		case i == 1:
+			return "one"
+		case i == 2:
+			return "two"
+		case mod100 >= 3 && mod100 <= 10:
+			return "few"
+		case mod100 >= 11:
+			return "many"
+		}
+	default:
+		if integer && i == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
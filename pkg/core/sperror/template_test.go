@@ -0,0 +1,215 @@
+package sperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lg   string
+		n    float64
+		want string
+	}{
+		{lg: En, n: 0, want: "other"},
+		{lg: En, n: 1, want: "one"},
+		{lg: En, n: 1.5, want: "other"},
+		{lg: "en-GB", n: 1, want: "one"},
+		{lg: Ru, n: 1, want: "one"},
+		{lg: Ru, n: 21, want: "one"},
+		{lg: Ru, n: 3, want: "few"},
+		{lg: Ru, n: 11, want: "many"},
+		{lg: Ru, n: 25, want: "many"},
+		{lg: Ru, n: 1.5, want: "other"},
+		{lg: Pl, n: 1, want: "one"},
+		{lg: Pl, n: 22, want: "few"},
+		{lg: Pl, n: 21, want: "many"},
+		{lg: Ar, n: 0, want: "zero"},
+		{lg: Ar, n: 2, want: "two"},
+		{lg: Ar, n: 103, want: "few"},
+		{lg: Ar, n: 111, want: "many"},
+		{lg: Ar, n: 100, want: "other"},
+		{lg: Fr, n: 0, want: "one"},
+		{lg: Ja, n: 1, want: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%v", tt.lg, tt.n), func(t *testing.T) {
+			if got := PluralCategory(tt.lg, tt.n); got != tt.want {
+				t.Errorf("PluralCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_MsgTemplate(t *testing.T) {
+	err := New(Sample{
+		Messages: map[string]string{
+			En: "Found {count, plural, =0 {no files} one {# file} other {# files}} in {dir}",
+			Ru: "Найдено {count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}} в {dir}",
+		},
+		Desc: "Quota {{user}} exceeded by {user}",
+		Hint: "Remove {count} files",
+	})
+
+	tests := []struct {
+		count any
+		lg    string
+		want  string
+	}{
+		{count: 0, lg: En, want: "Found no files in /tmp"},
+		{count: 1, lg: En, want: "Found 1 file in /tmp"},
+		{count: int64(5), lg: En, want: "Found 5 files in /tmp"},
+		{count: 1, lg: Ru, want: "Найдено 1 файл в /tmp"},
+		{count: 3, lg: Ru, want: "Найдено 3 файла в /tmp"},
+		{count: 11, lg: Ru, want: "Найдено 11 файлов в /tmp"},
+		{count: 1.5, lg: Ru, want: "Найдено 1.5 файла в /tmp"},
+		{count: "2", lg: En, want: "Found 2 files in /tmp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%v", tt.lg, tt.count), func(t *testing.T) {
+			e := err.Copy()
+			e.meta = map[string]any{"count": tt.count, "dir": "/tmp", "user": "bob"}
+			if got := e.Msg(tt.lg); got != tt.want {
+				t.Errorf("Msg() = %v, want %v", got, tt.want)
+			}
+			if got, want := e.Error(), "Quota {user} exceeded by bob: Remove "+fmt.Sprint(tt.count)+" files"; got != want {
+				t.Errorf("Error() = %v, want %v", got, want)
+			}
+			if got := e.MissingParams(); len(got) != 0 {
+				t.Errorf("MissingParams() = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestError_MissingParams(t *testing.T) {
+	err := New(Sample{
+		Messages: map[string]string{
+			En: "User {name} has {count, plural, one {# item} other {# items}}",
+			Ru: "У {name} нет прав",
+		},
+		Desc: "Request {request_id} failed",
+		Hint: "Retry in {delay}",
+	}).AddMeta("delay", "5s")
+
+	if got, want := err.MissingParams(), []string{"name", "count", "request_id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingParams() = %v, want %v", got, want)
+	}
+	if got, want := err.Msg(En), "User  has "; got != want {
+		t.Errorf("Msg() = %q, want %q", got, want)
+	}
+	if got, want := err.Hint(), "Retry in 5s"; got != want {
+		t.Errorf("Hint() = %q, want %q", got, want)
+	}
+}
+
+func TestError_TemplateIdentity(t *testing.T) {
+	sample := Sample{
+		Messages: map[string]string{En: "User {name} not found"},
+		Desc:     "No user {name}",
+	}
+	a := New(sample).AddMeta("name", "alice")
+	b := New(sample).AddMeta("name", "bob")
+
+	if !errors.Is(a, b) {
+		t.Errorf("errors.Is() = false, want true for the same template with different params")
+	}
+
+	braces := Ensure(errors.New("unexpected token { at 1:5"))
+	if got, want := braces.Desc(), "unexpected token { at 1:5"; got != want {
+		t.Errorf("Ensure().Desc() = %q, want %q", got, want)
+	}
+	if got := braces.MissingParams(); len(got) != 0 {
+		t.Errorf("Ensure().MissingParams() = %v, want none", got)
+	}
+}
+
+func TestError_TemplateLiterals(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+	}{
+		{
+			name: "json",
+			err:  New(Sample{Desc: `bad body {"a":{"b":1}}`, Hint: "{ }"}),
+			want: `bad body {"a":{"b":1}}: { }`,
+		},
+		{
+			name: "not a plural",
+			err:  New(Sample{Desc: "set {a, b} is {n, select, x {y}}"}).AddMeta("a", 1).AddMeta("n", 2),
+			want: "set {a, b} is {n, select, x {y}}",
+		},
+		{
+			name: "placeholders",
+			err:  New(Sample{Desc: "user {user.id} has {n, plural, one {# item} other {# items}}"}).AddMeta("user.id", 7).AddMeta("n", 2),
+			want: "user 7 has 2 items",
+		},
+		{
+			name: "helper",
+			err:  Internal(nil, `failed to parse {"id":1}`, "check {payload}"),
+			want: `failed to parse {"id":1}: check {payload}`,
+		},
+		{
+			name: "escaped",
+			err:  New(Sample{Desc: EscapeTemplate("{user} {{")}).AddMeta("user", "bob"),
+			want: "{user} {{",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_Plain(t *testing.T) {
+	plain := Ensure(errors.New("bad {user} {{"))
+	if plain.Core.Desc != "bad {user} {{" || plain.Desc() != "bad {user} {{" || !plain.Plain() {
+		t.Errorf("Ensure() desc = %q, rendered %q, want the text as is", plain.Core.Desc, plain.Desc())
+	}
+	if missing := plain.AddMeta("user", "bob").MissingParams(); missing != nil {
+		t.Errorf("MissingParams() = %v, want nil", missing)
+	}
+
+	data, err := json.Marshal(plain.AddMeta("user", "bob"))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	decoded := &Error{}
+	if err = json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !decoded.Plain() || decoded.Desc() != "bad {user} {{" {
+		t.Errorf("decoded desc = %q, plain %v, want the text as is", decoded.Desc(), decoded.Plain())
+	}
+
+	mixed := Ensure(errors.New("bad {user}")).AddMeta("user", "bob").SetHint("ask {user}")
+	if mixed.Plain() || mixed.Error() != "bad {user}: ask bob" {
+		t.Errorf("Error() after SetHint = %q, want the plain desc and the rendered hint", mixed.Error())
+	}
+}
+
+func ExampleError_MissingParams() {
+	err := New(Sample{
+		Messages: map[string]string{
+			En: "File {file} is larger than {limit, plural, one {# megabyte} other {# megabytes}}",
+		},
+	}).AddMeta("file", "report.pdf")
+
+	fmt.Println(err.MissingParams())
+
+	err.AddMeta("limit", 10)
+	fmt.Println(err.Msg(En))
+	// Output:
+	// [limit]
+	// File report.pdf is larger than 10 megabytes
+}
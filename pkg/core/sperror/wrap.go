@@ -4,25 +4,50 @@ import (
 	"errors"
 )
 
-// Unwrap returns the underlying error if it exists; otherwise, it returns the cause of the error.
-func (e *Error) Unwrap() error {
+// Unwrap returns the underlying layer, if any, followed by the cause, if any.
+// It returns nil for an error without both, so errors.Is and errors.As walk the whole chain,
+// including causes joined with errors.Join.
+func (e *Error) Unwrap() []error {
+	var errs []error
 	if e.underlying != nil {
-		return e.underlying
+		errs = append(errs, e.underlying)
 	}
 	if e.Core.Cause != nil {
-		return e.Core.Cause
+		errs = append(errs, e.Core.Cause)
 	}
-	return errors.New(e.Error())
+	return errs
+}
+
+// As finds the first cause in the chain that matches target, and if one is found, sets target to that value.
+//
+// Causes are checked layer by layer starting from the outer one, so the cause closest to the caller wins.
+// Each cause is searched with errors.As, so causes joined with errors.Join or wrapped with fmt.Errorf are found too.
+//
+// Usage:
+//
+//	var pgErr *pgconn.PgError
+//	if errors.As(err, &pgErr) {
+//		// pgErr is the cause of some layer of err
+//	}
+func (e *Error) As(target any) bool {
+	for cause := range e.Causes() {
+		if errors.As(cause, target) {
+			return true
+		}
+	}
+	return false
 }
 
 // Wrap wraps src into e's cause Error
 func (e *Error) Wrap(err error) *Error {
+	e = e.writable()
 	e.Core.Cause = err
 	return e
 }
 
 // Wrap wraps `src` into existing Error.
 func Wrap(src *Error, dst *Error) *Error {
+	dst = dst.writable()
 	dst.underlying = src
 	dst.remainsUnderlying = src.remainsUnderlying + 1
 	return dst
@@ -30,8 +55,8 @@ func Wrap(src *Error, dst *Error) *Error {
 
 // WrapNew wraps `src` into new-initialized Error from provided Sample.
 func WrapNew(src *Error, dst Sample) *Error {
-	res := New(dst)
+	res := newAt(dst, 1)
 	res.underlying = src
 	res.remainsUnderlying = src.remainsUnderlying + 1
-	return res.path(1)
+	return res
 }
@@ -1,7 +1,10 @@
 package sperror
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -14,7 +17,7 @@ func ExampleError_Unwrap() {
 
 	outer := New(Sample{}).Wrap(inner)
 
-	err := outer.Unwrap()
+	err := outer.Unwrap()[0]
 	fmt.Printf("%v\nType: %T\n\n", err, err)
 	e := Ensure(err).Unwrap()
 	fmt.Printf("%v", e)
@@ -24,7 +27,7 @@ func ExampleError_Unwrap() {
 	// Inner error description: Try again later
 	// Type: *sperror.Error
 	//
-	// example error
+	// [example error]
 }
 
 func ExampleError_Wrap() {
@@ -40,7 +43,7 @@ func ExampleError_Wrap() {
 
 	fmt.Printf("%v\n", outer.Unwrap())
 	// Output:
-	// Inner error: hint
+	// [Inner error: hint]
 }
 
 func ExampleWrap() {
@@ -66,34 +69,38 @@ func ExampleWrapNew() {
 	wrapped := WrapNew(src, Sample{})
 
 	fmt.Printf("%v\n", wrapped.Unwrap())
-	// Output: Application error description: hint
+	// Output: [Application error description: hint]
 }
 
 func TestError_Unwrap(t *testing.T) {
 	tests := []struct {
 		name string
 		err  *Error
-		want error
+		want []string
 	}{
 		{
-			name: "unwrap with underlying error",
+			name: "unwrap with error cause",
 			err: New(Sample{}).Wrap(New(Sample{
 				Desc:  "Inner error",
 				Hint:  "hint",
 				Cause: fmt.Errorf("cause"),
 			})),
-			want: New(Sample{
-				Desc:  "Inner error",
-				Hint:  "hint",
-				Cause: fmt.Errorf("cause"),
-			}),
+			want: []string{"Inner error: hint"},
 		},
 		{
 			name: "unwrap with cause only",
 			err: New(Sample{
 				Cause: fmt.Errorf("cause"),
 			}),
-			want: fmt.Errorf("cause"),
+			want: []string{"cause"},
+		},
+		{
+			name: "unwrap with underlying error and cause",
+			err: WrapNew(New(Sample{Desc: "Inner error", Hint: "hint"}), Sample{
+				Desc:  "Outer error",
+				Cause: fmt.Errorf("cause"),
+			}),
+			want: []string{"Inner error: hint", "cause"},
 		},
 		{
 			name: "unwrap with desc and hint only",
@@ -101,19 +108,95 @@ func TestError_Unwrap(t *testing.T) {
 				Desc: "Inner error",
 				Hint: "hint",
 			}),
-			want: fmt.Errorf("Inner error: hint"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.err.Unwrap(); got.Error() != tt.want.Error() {
+			var got []string
+			for _, err := range tt.err.Unwrap() {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Error.Unwrap() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+type codeError struct {
+	code int
+}
+
+func (c *codeError) Error() string {
+	return fmt.Sprintf("code %d", c.code)
+}
+
+func TestError_As(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantOk   bool
+	}{
+		{
+			name:     "cause of the outer layer",
+			err:      Internal(&codeError{1}, "failed", "retry"),
+			wantCode: 1,
+			wantOk:   true,
+		},
+		{
+			name:     "cause under a wrapped layer",
+			err:      WrapNew(Internal(fmt.Errorf("query: %w", &codeError{2}), "query failed", "check db"), Sample{Desc: "service failed"}),
+			wantCode: 2,
+			wantOk:   true,
+		},
+		{
+			name:     "closest cause wins",
+			err:      WrapNew(Internal(&codeError{3}, "inner", "inner"), Sample{Desc: "outer", Cause: &codeError{4}}),
+			wantCode: 4,
+			wantOk:   true,
+		},
+		{
+			name:     "joined cause",
+			err:      Internal(errors.Join(errors.New("a"), &codeError{5}), "failed", "retry"),
+			wantCode: 5,
+			wantOk:   true,
+		},
+		{
+			name: "not found",
+			err:  Any(errors.New("a"), "failed", "retry"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target *codeError
+			ok := errors.As(tt.err, &target)
+			if ok != tt.wantOk {
+				t.Fatalf("errors.As() = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && target.code != tt.wantCode {
+				t.Errorf("errors.As() code = %v, want %v", target.code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestError_DeepIsJoin(t *testing.T) {
+	err := WrapNew(Internal(errors.Join(errors.New("a"), sql.ErrNoRows), "query failed", "check db"), Sample{Desc: "service failed"})
+
+	if !err.DeepIs(sql.ErrNoRows) {
+		t.Errorf("DeepIs() = false, want true")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("errors.Is() = false, want true")
+	}
+	if err.DeepIs(sql.ErrTxDone) {
+		t.Errorf("DeepIs() = true, want false")
+	}
+}
+
 func TestError_Wrap(t *testing.T) {
 	tests := []struct {
 		name string
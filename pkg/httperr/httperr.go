@@ -0,0 +1,200 @@
+// Package httperr adapts handlers that return errors to net/http.
+//
+// Returned errors are converted with sperror.Ensure, logged with their full chain and rendered
+// to the client spun to levels.LevelUser, so internals never leak into responses.
+package httperr
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jszwec/csvutil"
+	"github.com/s4bb4t/lighthouse/internal/header"
+	"github.com/s4bb4t/lighthouse/pkg/core"
+	"github.com/s4bb4t/lighthouse/pkg/core/export"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/logger"
+)
+
+type (
+	// HandlerFunc is an HTTP handler that reports failures by returning an error.
+	HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+	// Handler is an http.Handler that renders errors returned by HandlerFunc.
+	Handler struct {
+		h   HandlerFunc
+		log core.Logger
+	}
+
+	// Response is the body written for a failed request.
+	Response struct {
+		XMLName xml.Name `json:"-" xml:"error" csv:"-"`
+		Message string   `json:"message" xml:"message" csv:"message"`
+		Desc    string   `json:"desc,omitempty" xml:"desc,omitempty" csv:"desc,omitempty"`
+		Hint    string   `json:"hint,omitempty" xml:"hint,omitempty" csv:"hint,omitempty"`
+		Code    int      `json:"code" xml:"code" csv:"code"`
+		ErrCode string   `json:"error_code,omitempty" xml:"error_code,omitempty" csv:"error_code,omitempty"`
+		Fields  []Field  `json:"fields,omitempty" xml:"field,omitempty" csv:"-"`
+	}
+
+	// Field is an invalid field of a request, see sperror.Group.
+	Field struct {
+		Field   string `json:"field" xml:"name,attr"`
+		Message string `json:"message" xml:"message"`
+		ErrCode string `json:"error_code,omitempty" xml:"error_code,omitempty"`
+	}
+)
+
+// Handle returns an http.Handler that calls h and renders the returned error.
+//
+// The error is logged through log with the full chain, while the client receives only the layer
+// returned by Spin(levels.LevelUser). The message language is picked from Accept-Language against
+// the error's messages, the body format (JSON, XML or CSV) from Accept.
+// If log is nil, errors are not logged.
+func Handle(log core.Logger, h HandlerFunc) *Handler {
+	if log == nil {
+		log = logger.Noop()
+	}
+	return &Handler{h: h, log: log}
+}
+
+// ServeHTTP implements http.Handler.
+// If the handler has already started the response, the error is only logged, the response is left as is.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w := &responseWriter{ResponseWriter: rw}
+	err := h.h(w, r)
+	if err == nil {
+		return
+	}
+
+	e := sperror.Ensure(err)
+	h.log.ErrorWithLevel(e, levels.LevelDebug)
+
+	if !w.started {
+		Write(w, r, e)
+	}
+}
+
+// Write renders e as the response to r.
+// It can be used directly by handlers that do not return errors.
+// The error is redacted with sperror.DefaultPolicy.
+// Status codes that cannot end a response, e.g. 1xx or codes out of 100-999, are replaced with 500.
+// If the error has a retry delay (see sperror.Error.RetryAfter), it is sent in the Retry-After header.
+func Write(w http.ResponseWriter, r *http.Request, e *sperror.Error) {
+	e = e.Redact(sperror.DefaultPolicy())
+	resp, lang := response(e, r.Header.Get("Accept-Language"))
+
+	var (
+		body []byte
+		err  error
+	)
+	ct := negotiate(r.Header.Get("Accept"))
+	switch ct {
+	case export.Xml:
+		body, err = xml.Marshal(resp)
+	case export.Csv:
+		body, err = csvutil.Marshal([]Response{resp})
+	default:
+		body, err = json.Marshal(resp)
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ct+"; charset=utf-8")
+	if lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if after := e.RetryAfter(); after > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(after.Seconds()))))
+	}
+	w.WriteHeader(resp.Code)
+	w.Write(body)
+}
+
+// response builds the user-safe body of e in the best language for acceptLanguage, see sperror.Error.MsgFor.
+func response(e *sperror.Error, acceptLanguage string) (Response, string) {
+	code := status(e.Code())
+
+	// the outer layer is not meant for users at all, so only the status is safe to show
+	if e.Level() > levels.LevelUser {
+		return Response{Message: http.StatusText(code), Code: code}, ""
+	}
+
+	u := e.Spin(levels.LevelUser)
+	if u.Code() != 0 {
+		code = status(u.Code())
+	}
+
+	lang := u.LangFor(acceptLanguage)
+	msg := u.Msg(lang)
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+
+	var fields []Field
+	for _, c := range u.Children() {
+		fields = append(fields, Field{Field: c.Field, Message: c.Err.MsgFor(acceptLanguage, lang), ErrCode: c.Err.ErrCode()})
+	}
+
+	return Response{
+		Message: msg,
+		Desc:    u.Desc(),
+		Hint:    u.Hint(),
+		Code:    code,
+		ErrCode: u.ErrCode(),
+		Fields:  fields,
+	}, lang
+}
+
+// status returns code if it can be sent as the final status of a response, 500 otherwise.
+// Codes come from catalogs and remote problems, and net/http panics on codes out of 100-999.
+func status(code int) int {
+	if code < 200 || code > 999 {
+		return http.StatusInternalServerError
+	}
+	return code
+}
+
+// negotiate returns the supported content type that fits the Accept header best.
+func negotiate(accept string) string {
+	for _, mt := range header.Weighted(accept) {
+		switch strings.ToLower(mt) {
+		case export.Json, "*/*", "application/*":
+			return export.Json
+		case export.Xml, "text/xml":
+			return export.Xml
+		case export.Csv:
+			return export.Csv
+		}
+	}
+	return export.Json
+}
+
+// responseWriter records whether the response has been started.
+type responseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the original writer, e.g. to flush it.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,199 @@
+package httperr
+
+import (
+	"errors"
+	"github.com/s4bb4t/lighthouse/pkg/core/export"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errNotFound = sperror.New(sperror.Sample{
+	Messages: map[string]string{
+		sperror.En: "Not found",
+		sperror.Ru: "Не найдено",
+		sperror.Pt: "Não encontrado",
+	},
+	Desc:     "User not found",
+	Hint:     "Check user id",
+	HttpCode: http.StatusNotFound,
+	Level:    levels.LevelUser,
+})
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		accept    string
+		language  string
+		wantCode  int
+		wantType  string
+		wantLang  string
+		wantBody  string
+		wantRetry string
+	}{
+		{
+			name:     "no error",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "json",
+			err:      errNotFound,
+			accept:   "application/json",
+			language: "ru-RU,ru;q=0.9,en;q=0.8",
+			wantCode: http.StatusNotFound,
+			wantType: export.Json,
+			wantLang: sperror.Ru,
+			wantBody: `{"message":"Не найдено","desc":"User not found","hint":"Check user id","code":404}`,
+		},
+		{
+			name:     "region fallback",
+			err:      errNotFound,
+			language: "pt-BR",
+			wantCode: http.StatusNotFound,
+			wantType: export.Json,
+			wantLang: sperror.Pt,
+			wantBody: `{"message":"Não encontrado","desc":"User not found","hint":"Check user id","code":404}`,
+		},
+		{
+			name:     "xml",
+			err:      errNotFound,
+			accept:   "text/html;q=0.9, application/xml",
+			language: "de",
+			wantCode: http.StatusNotFound,
+			wantType: export.Xml,
+			wantLang: sperror.En,
+			wantBody: `<error><message>Not found</message><desc>User not found</desc><hint>Check user id</hint><code>404</code></error>`,
+		},
+		{
+			name:     "csv",
+			err:      errNotFound,
+			accept:   "text/csv",
+			wantCode: http.StatusNotFound,
+			wantType: export.Csv,
+			wantLang: sperror.En,
+			wantBody: "message,desc,hint,code,error_code\nNot found,User not found,Check user id,404,\n",
+		},
+		{
+			name:     "error code",
+			err:      errNotFound.Copy().SetErrCode("users.not_found"),
+			wantCode: http.StatusNotFound,
+			wantType: export.Json,
+			wantLang: sperror.En,
+			wantBody: `{"message":"Not found","desc":"User not found","hint":"Check user id","code":404,"error_code":"users.not_found"}`,
+		},
+		{
+			name:     "field errors",
+			err:      new(sperror.Group).Add("user.email", errNotFound.Copy().SetErrCode("users.not_found")).Err(),
+			language: "ru",
+			wantCode: http.StatusNotFound,
+			wantType: export.Json,
+			wantLang: sperror.Ru,
+			wantBody: `{"message":"Ошибка валидации","desc":"1 field error(s): user.email: User not found","hint":"Fix the listed fields","code":404,` +
+				`"fields":[{"field":"user.email","message":"Не найдено","error_code":"users.not_found"}]}`,
+		},
+		{
+			name: "spin to user level",
+			err: sperror.WrapNew(sperror.New(sperror.Sample{
+				Desc:  "query failed",
+				Hint:  "check db",
+				Level: levels.LevelError,
+				Cause: errors.New("db closed"),
+			}), sperror.Sample{
+				Messages: map[string]string{sperror.En: "Try again later"},
+				HttpCode: http.StatusServiceUnavailable,
+				Level:    levels.LevelUser,
+			}),
+			wantCode: http.StatusServiceUnavailable,
+			wantType: export.Json,
+			wantLang: sperror.En,
+			wantBody: `{"message":"Try again later","code":503}`,
+		},
+		{
+			name: "retry after",
+			err: sperror.New(sperror.Sample{
+				Messages:   map[string]string{sperror.En: "Slow down"},
+				HttpCode:   http.StatusTooManyRequests,
+				Level:      levels.LevelUser,
+				RetryAfter: 1500 * time.Millisecond,
+			}),
+			wantCode:  http.StatusTooManyRequests,
+			wantType:  export.Json,
+			wantLang:  sperror.En,
+			wantBody:  `{"message":"Slow down","code":429}`,
+			wantRetry: "2",
+		},
+		{
+			name:     "invalid status",
+			err:      errNotFound.Copy().SetCode(1000),
+			wantCode: http.StatusInternalServerError,
+			wantType: export.Json,
+			wantLang: sperror.En,
+			wantBody: `{"message":"Not found","desc":"User not found","hint":"Check user id","code":500}`,
+		},
+		{
+			name:     "informational status",
+			err:      errNotFound.Copy().SetCode(http.StatusContinue),
+			wantCode: http.StatusInternalServerError,
+			wantType: export.Json,
+			wantLang: sperror.En,
+			wantBody: `{"message":"Not found","desc":"User not found","hint":"Check user id","code":500}`,
+		},
+		{
+			name:     "plain error",
+			err:      errors.New("db closed"),
+			wantCode: http.StatusInternalServerError,
+			wantType: export.Json,
+			wantBody: `{"message":"Internal Server Error","code":500}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handle(nil, func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			r.Header.Set("Accept-Language", tt.language)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("code = %v, want %v", w.Code, tt.wantCode)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.wantType) {
+				t.Errorf("Content-Type = %v, want %v", ct, tt.wantType)
+			}
+			if lang := w.Header().Get("Content-Language"); lang != tt.wantLang {
+				t.Errorf("Content-Language = %v, want %v", lang, tt.wantLang)
+			}
+			if body := w.Body.String(); body != tt.wantBody {
+				t.Errorf("body = %v, want %v", body, tt.wantBody)
+			}
+			if retry := w.Header().Get("Retry-After"); retry != tt.wantRetry {
+				t.Errorf("Retry-After = %v, want %v", retry, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestHandle_Started(t *testing.T) {
+	h := Handle(nil, func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		return errNotFound
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("response = %v %q, want %v %q", w.Code, w.Body.String(), http.StatusAccepted, "partial")
+	}
+}
@@ -19,11 +19,12 @@ const (
 )
 
 type Logger struct {
-	pd    func(layers int) string
-	log   *slog.Logger
-	stage string
-	lg    string
-	noop  bool
+	pd     func(layers int) string
+	log    *slog.Logger
+	stage  string
+	lg     string
+	noop   bool
+	policy *sperror.Policy
 }
 
 // Noop - creates new Logger that does nothing
@@ -51,7 +52,7 @@ func New(stage, lg string, out io.Writer) *Logger {
 		out = os.Stdout
 	}
 
-	l := &Logger{lg: lg, stage: stage, pd: func(layers int) string {
+	l := &Logger{lg: lg, stage: stage, policy: sperror.DefaultPolicy(), pd: func(layers int) string {
 		_, file, line, ok := runtime.Caller(layers + 1)
 		if ok {
 			absPath, err := filepath.Abs(file)
@@ -74,6 +75,14 @@ func New(stage, lg string, out io.Writer) *Logger {
 	return l
 }
 
+// SetRedaction - sets the policy applied to errors before they are logged
+//
+// New loggers use sperror.DefaultPolicy, nil disables redaction
+func (l *Logger) SetRedaction(p *sperror.Policy) *Logger {
+	l.policy = p
+	return l
+}
+
 // With - adds fields to logger
 //
 // It's a shortcut for slog.With()
@@ -99,7 +108,7 @@ func (l *Logger) Warn(msg string, e error, args ...any) {
 		return
 	}
 	if e != nil {
-		args = append(args, hooks.Slog(sperror.Ensure(e), levels.LevelError)...)
+		args = append(args, hooks.Slog(sperror.Ensure(e).Redact(l.policy), levels.LevelError)...)
 	}
 	l.log.Warn(msg, args...)
 }
@@ -109,10 +118,10 @@ func (l *Logger) ErrorWithLevel(e error, lvl levels.Level) {
 	if l.noop || e == nil {
 		return
 	}
-	err := sperror.Ensure(e)
+	err := sperror.Ensure(e).Redact(l.policy)
 	// spin-prepare and log error
 	args := hooks.Slog(err, lvl)
-	l.log.Error(err.Msg(l.lg), args...)
+	l.log.Error(err.MsgFor(l.lg), args...)
 }
 
 // Error - logs error with default Error level
@@ -122,10 +131,10 @@ func (l *Logger) Error(e error) {
 	if l.noop || e == nil {
 		return
 	}
-	err := sperror.Ensure(e)
+	err := sperror.Ensure(e).Redact(l.policy)
 	// spin-prepare and log error
 	args := hooks.Slog(err, levels.LevelError)
-	l.log.Error(err.Msg(l.lg), args...)
+	l.log.Error(err.MsgFor(l.lg), args...)
 }
 
 // Debug - prints additional debug log to Logger's out
@@ -0,0 +1,183 @@
+// Package recovery converts panics into *sperror.Error values, logs them and sends alerts.
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/s4bb4t/lighthouse/pkg/core"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/httperr"
+	"github.com/s4bb4t/lighthouse/pkg/logger"
+)
+
+// Recoverer recovers panics, reports them through core.Logger and core.Notify
+// and optionally re-raises them.
+type Recoverer struct {
+	log     core.Logger
+	notify  core.Notify
+	group   string
+	repanic bool
+}
+
+// New creates a Recoverer.
+//
+// log and notify may be nil - then panics are not logged or alerted respectively.
+// By default alerts are sent to all subscribers and panics are not re-raised.
+func New(log core.Logger, notify core.Notify) *Recoverer {
+	if log == nil {
+		log = logger.Noop()
+	}
+	return &Recoverer{log: log, notify: notify}
+}
+
+// SetGroup sets the notification group alerts are sent to, e.g. telegram.DefaultDevGroup.
+func (r *Recoverer) SetGroup(group string) *Recoverer {
+	r.group = group
+	return r
+}
+
+// SetRepanic sets whether the original panic is re-raised after it was reported.
+func (r *Recoverer) SetRepanic(repanic bool) *Recoverer {
+	r.repanic = repanic
+	return r
+}
+
+// Recover is a deferred helper that recovers a panic of the current goroutine.
+//
+// Usage:
+//
+//	defer rec.Recover()
+func (r *Recoverer) Recover() {
+	if v := recover(); v != nil {
+		r.Handle(v)
+	}
+}
+
+// Handle reports the recovered value v and re-raises it if configured to.
+// It is meant for deferred functions that call recover() themselves.
+func (r *Recoverer) Handle(v any) {
+	if v == nil {
+		return
+	}
+	r.Report(v)
+	if r.repanic {
+		panic(v)
+	}
+}
+
+// Report converts the recovered value v into an *Error, logs it and sends an alert.
+// The returned error has levels.LevelDebug, v as its cause and the stack of the panic.
+func (r *Recoverer) Report(v any) *sperror.Error {
+	return r.ReportCtx(context.Background(), v)
+}
+
+// ReportCtx is like Report, but also adds correlation ids of ctx to the error, see sperror.Error.WithContext.
+func (r *Recoverer) ReportCtx(ctx context.Context, v any) *sperror.Error {
+	cause, ok := v.(error)
+	if !ok {
+		cause = fmt.Errorf("%v", v)
+	}
+
+	e := sperror.New(sperror.Sample{
+		Messages: map[string]string{
+			sperror.En: "Panic recovered",
+		},
+		Desc:     "panic: " + cause.Error(),
+		Hint:     "Check the stack trace for the panic site",
+		Plain:    true,
+		HttpCode: http.StatusInternalServerError,
+		Level:    levels.LevelDebug,
+		Cause:    cause,
+	}).WithContext(ctx).SetStack()
+
+	// the frame right after runtime.gopanic is the place where panic was called
+	st := e.StackTrace()
+	for i, f := range st {
+		if f.Function == "runtime.gopanic" && i+1 < len(st) {
+			if site := st[i+1]; !strings.HasPrefix(site.Function, "runtime.") {
+				e.Core.Source = fmt.Sprintf("%s:%d", site.File, site.Line)
+			}
+			break
+		}
+	}
+
+	r.log.ErrorWithLevel(e, levels.LevelDebug)
+
+	if r.notify != nil {
+		var err error
+		if r.group != "" {
+			err = r.notify.Error(e, r.group)
+		} else {
+			err = r.notify.Error(e)
+		}
+		if err != nil {
+			r.log.Error(err)
+		}
+	}
+
+	return e
+}
+
+// Go runs fn in a new goroutine.
+// An error returned by fn is logged, a panic is recovered and reported.
+func (r *Recoverer) Go(fn func() error) {
+	go func() {
+		defer r.Recover()
+		if err := fn(); err != nil {
+			r.log.Error(err)
+		}
+	}()
+}
+
+// Middleware recovers panics of next, reports them and responds with a safe 500 error.
+// If the handler has already started the response, the panic is only reported, the response is left as is.
+// http.ErrAbortHandler is re-raised untouched, as net/http expects.
+func (r *Recoverer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		w := &responseWriter{ResponseWriter: rw}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+
+			err := r.ReportCtx(req.Context(), v)
+			if !w.started {
+				httperr.Write(w, req, err)
+			}
+			if r.repanic {
+				panic(v)
+			}
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// responseWriter records whether the response has been started.
+type responseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the original writer, e.g. to flush it.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
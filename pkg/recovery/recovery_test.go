@@ -0,0 +1,163 @@
+package recovery
+
+import (
+	"errors"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/telegram"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type notifier struct {
+	errs   chan error
+	groups chan []string
+}
+
+func newNotifier() *notifier {
+	return &notifier{errs: make(chan error, 1), groups: make(chan []string, 1)}
+}
+
+func (n *notifier) Info(string) error { return nil }
+
+func (n *notifier) Error(err error, group ...string) error {
+	n.errs <- err
+	n.groups <- group
+	return nil
+}
+
+var errPanic = errors.New("boom")
+
+func TestRecoverer_Recover(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     any
+		group     string
+		repanic   bool
+		wantCause string
+	}{
+		{
+			name:      "error value",
+			value:     errPanic,
+			group:     telegram.DefaultDevGroup,
+			wantCause: "boom",
+		},
+		{
+			name:      "any value",
+			value:     42,
+			wantCause: "42",
+		},
+		{
+			name:      "struct value",
+			value:     struct{ A int }{1},
+			wantCause: "{1}",
+		},
+		{
+			name:      "map value",
+			value:     map[string]string{"user": "x"},
+			wantCause: "map[user:x]",
+		},
+		{
+			name:      "repanic",
+			value:     "fatal",
+			repanic:   true,
+			wantCause: "fatal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNotifier()
+			r := New(nil, n).SetGroup(tt.group).SetRepanic(tt.repanic)
+
+			repanicked := func() (v any) {
+				defer func() { v = recover() }()
+				func() {
+					defer r.Recover()
+					panic(tt.value)
+				}()
+				return nil
+			}()
+			if (repanicked != nil) != tt.repanic {
+				t.Errorf("re-raised = %v, want %v", repanicked, tt.repanic)
+			}
+
+			e := sperror.Ensure(<-n.errs)
+			if e.Level() != levels.LevelDebug {
+				t.Errorf("Level() = %v, want %v", e.Level(), levels.LevelDebug)
+			}
+			if e.Caused() == nil || e.Caused().Error() != tt.wantCause {
+				t.Errorf("Caused() = %v, want %v", e.Caused(), tt.wantCause)
+			}
+			if got, want := e.Desc(), "panic: "+tt.wantCause; got != want {
+				t.Errorf("Desc() = %q, want %q", got, want)
+			}
+			if v, ok := tt.value.(error); ok && !errors.Is(e, v) {
+				t.Errorf("errors.Is() = false, want true")
+			}
+			if len(e.StackTrace()) == 0 {
+				t.Errorf("StackTrace() is empty")
+			}
+			if !strings.Contains(e.Source(), "recovery_test.go") {
+				t.Errorf("Source() = %v, want panic site", e.Source())
+			}
+
+			groups := <-n.groups
+			if tt.group == "" && len(groups) != 0 || tt.group != "" && (len(groups) != 1 || groups[0] != tt.group) {
+				t.Errorf("groups = %v, want %v", groups, tt.group)
+			}
+		})
+	}
+}
+
+func TestRecoverer_Go(t *testing.T) {
+	n := newNotifier()
+	New(nil, n).Go(func() error {
+		panic(errPanic)
+	})
+
+	if err := <-n.errs; !errors.Is(err, errPanic) {
+		t.Errorf("Error() = %v, want %v", err, errPanic)
+	}
+}
+
+func TestRecoverer_Middleware(t *testing.T) {
+	n := newNotifier()
+	h := New(nil, n).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(errPanic)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("code = %v, want %v", w.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("body = %v, leaks the panic value", w.Body.String())
+	}
+	if err := <-n.errs; !errors.Is(err, errPanic) {
+		t.Errorf("Error() = %v, want %v", err, errPanic)
+	}
+}
+
+func TestRecoverer_MiddlewareStarted(t *testing.T) {
+	n := newNotifier()
+	h := New(nil, n).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic(errPanic)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("response = %v %q, want %v %q", w.Code, w.Body.String(), http.StatusAccepted, "partial")
+	}
+	if err := <-n.errs; !errors.Is(err, errPanic) {
+		t.Errorf("Error() = %v, want %v", err, errPanic)
+	}
+}
@@ -0,0 +1,163 @@
+package render
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+)
+
+type (
+	// Card is the data of a rendered error, the dot of Target templates.
+	Card struct {
+		Level    string // level number, as in logs
+		Code     string
+		HttpCode int
+		Message  string
+		Desc     string
+		Hint     string
+		Source   string
+		Cause    string
+		Fields   []Field  // field errors of an error built by sperror.Group
+		Request  []Entry  // meta of the registered context extractors, in their order
+		Meta     []Entry  // the rest of the meta, sorted by key, nested maps have dotted keys
+		Stack    []string // frames, innermost first
+	}
+
+	// Field is a field error of a Card.
+	Field struct {
+		Name    string
+		Message string
+		Desc    string
+	}
+
+	// Entry is a meta entry of a Card.
+	Entry struct {
+		Key   string
+		Value string
+	}
+)
+
+// NewCard returns the card of the deepest layer of err passed through by Spin(levels.LevelDebug),
+// with messages in lang and the request context collected from the whole chain.
+func NewCard(err error, lang string) Card {
+	full := sperror.Ensure(err)
+	return newCard(full.Spin(levels.LevelDebug), lang, full.ContextMeta())
+}
+
+// LayerCard returns the card of the single layer e with messages in lang.
+// Unlike NewCard, it does not pass through the chain: the request context is taken from the meta of e only.
+func LayerCard(e *sperror.Error, lang string) Card {
+	meta := e.AllMeta()
+	ctx := make(map[string]any)
+	for _, key := range sperror.ContextKeys() {
+		if v, ok := meta[key]; ok {
+			ctx[key] = v
+		}
+	}
+	return newCard(e, lang, ctx)
+}
+
+// newCard returns the card of the layer e with the request context ctx.
+// The cause of a layer with field errors is their join, so it is left out.
+func newCard(e *sperror.Error, lang string, ctx map[string]any) Card {
+	c := Card{
+		Level:    e.Level().String(),
+		Code:     e.ErrCode(),
+		HttpCode: e.Code(),
+		Message:  e.MsgFor(lang),
+		Desc:     e.Desc(),
+		Hint:     e.Hint(),
+		Source:   e.Source(),
+	}
+	if e.Caused() != nil && len(e.Children()) == 0 {
+		c.Cause = e.Caused().Error()
+	}
+	for _, child := range e.Children() {
+		c.Fields = append(c.Fields, Field{Name: child.Field, Message: child.Err.MsgFor(lang), Desc: child.Err.Desc()})
+	}
+	for _, f := range e.StackTrace() {
+		c.Stack = append(c.Stack, f.String())
+	}
+
+	for _, key := range sperror.ContextKeys() {
+		if v, ok := ctx[key]; ok {
+			c.Request = append(c.Request, Entry{Key: key, Value: fmt.Sprint(v)})
+		}
+	}
+	meta := e.AllMeta()
+	for key := range ctx {
+		delete(meta, key)
+	}
+	c.Meta = entries(meta, "")
+	return c
+}
+
+// entries returns the entries of meta sorted by key, with nested maps flattened to dotted keys.
+func entries(meta map[string]any, prefix string) []Entry {
+	var res []Entry
+	for _, key := range slices.Sorted(maps.Keys(meta)) {
+		if nested, ok := meta[key].(map[string]any); ok {
+			res = append(res, entries(nested, prefix+key+".")...)
+			continue
+		}
+		res = append(res, Entry{Key: prefix + key, Value: fmt.Sprint(meta[key])})
+	}
+	return res
+}
+
+// shorten returns a copy of the card with at most frames stack frames and texts cut to max runes.
+// A negative max leaves texts as they are, a zero one drops meta as well.
+func (c Card) shorten(frames, max int) Card {
+	c.Stack = c.Stack[:min(frames, len(c.Stack))]
+	if max < 0 {
+		return c
+	}
+	if max == 0 {
+		c.Request, c.Meta = nil, nil
+		max = 64
+	}
+
+	c.Message, c.Desc, c.Hint = cut(c.Message, max), cut(c.Desc, max), cut(c.Hint, max)
+	c.Source, c.Cause = cut(c.Source, max), cut(c.Cause, max)
+	c.Fields = slices.Clone(c.Fields)
+	for i, f := range c.Fields {
+		c.Fields[i] = Field{Name: cut(f.Name, max), Message: cut(f.Message, max), Desc: cut(f.Desc, max)}
+	}
+	c.Request, c.Meta = cutEntries(c.Request, max), cutEntries(c.Meta, max)
+	return c
+}
+
+func cutEntries(entries []Entry, max int) []Entry {
+	res := slices.Clone(entries)
+	for i, e := range res {
+		res[i] = Entry{Key: cut(e.Key, max), Value: cut(e.Value, max)}
+	}
+	return res
+}
+
+// cut joins the lines of s and cuts it to max runes, the last of which is an ellipsis.
+// Shortened texts are kept on one line so that cutLines never splits them.
+func cut(s string, max int) string {
+	r := []rune(strings.ReplaceAll(s, "\n", " "))
+	if len(r) <= max {
+		return string(r)
+	}
+	return string(r[:max-1]) + "…"
+}
+
+// cutLines cuts s to max runes at the end of a line, the last rune is an ellipsis on a line of its own.
+func cutLines(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	i := max - 1
+	for i > 0 && r[i-1] != '\n' {
+		i--
+	}
+	return string(r[:i]) + "…"
+}
@@ -0,0 +1,116 @@
+// Package render turns errors into human-readable cards for chats, issue trackers and reports.
+//
+// A Target defines the markup: how text is escaped, how code is quoted, the template of the card
+// and the length limit of the platform. Predefined targets are Markdown, Text, TelegramMarkdownV2,
+// TelegramHTML and Slack; templates are overridden with Target.WithTemplate.
+package render
+
+import (
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// Target is a rendering target.
+//
+// Templates are text/template templates executed with a Card. Besides the builtins they can call
+// esc to escape text, code to quote it as inline code, pre to quote it as a preformatted block
+// and lines to join a list with newlines.
+type Target struct {
+	Name string
+	// Limit is the maximum length of the rendered card in runes, 0 is unlimited.
+	// Longer cards lose stack frames first, then long texts are shortened, then meta is dropped,
+	// then field errors are. Cards that are still too long are cut at the end of a line.
+	Limit int
+
+	Escape func(s string) string // escapes plain text
+	Code   func(s string) string // quotes s as inline code, including the delimiters
+	Pre    func(s string) string // quotes s as a preformatted block, including the delimiters
+
+	Template string
+	tmpl     *template.Template
+}
+
+// WithTemplate returns a copy of the target that renders cards with text, see Target.
+//
+// Usage:
+//
+//	short, err := render.Slack.WithTemplate(`*{{esc .Message}}* {{code .Code}}`)
+func (t Target) WithTemplate(text string) (Target, error) {
+	tmpl, err := t.parse(text)
+	if err != nil {
+		return Target{}, err
+	}
+	t.Template, t.tmpl = text, tmpl
+	return t, nil
+}
+
+// Render renders the card of err with messages in lang, see NewCard.
+func (t Target) Render(err error, lang string) (string, error) {
+	return t.RenderCard(NewCard(err, lang))
+}
+
+// RenderCard renders c, shortening it to fit into Limit.
+// Texts are shortened before they are escaped and cards are only cut between lines,
+// so the markup stays valid as long as the template opens and closes it within lines.
+func (t Target) RenderCard(c Card) (string, error) {
+	tmpl := t.tmpl
+	if tmpl == nil {
+		var err error
+		if tmpl, err = t.parse(t.Template); err != nil {
+			return "", err
+		}
+	}
+
+	out, err := execute(tmpl, c)
+	if err != nil || t.Limit <= 0 || utf8.RuneCountInString(out) <= t.Limit {
+		return out, err
+	}
+
+	for frames := len(c.Stack) / 2; frames > 0; frames /= 2 {
+		if out, err = execute(tmpl, c.shorten(frames, -1)); err != nil || utf8.RuneCountInString(out) <= t.Limit {
+			return out, err
+		}
+	}
+	for _, max := range []int{1024, 256, 64, 0} {
+		if out, err = execute(tmpl, c.shorten(0, max)); err != nil || utf8.RuneCountInString(out) <= t.Limit {
+			return out, err
+		}
+	}
+	short := c.shorten(0, 0)
+	for fields := len(short.Fields) / 2; len(short.Fields) > 0; fields /= 2 {
+		short.Fields = short.Fields[:fields]
+		if out, err = execute(tmpl, short); err != nil || utf8.RuneCountInString(out) <= t.Limit {
+			return out, err
+		}
+	}
+	// the template itself is longer than the limit
+	return cutLines(out, t.Limit), nil
+}
+
+func (t Target) parse(text string) (*template.Template, error) {
+	return template.New(t.Name).Funcs(template.FuncMap{
+		"esc":   t.Escape,
+		"code":  t.Code,
+		"pre":   t.Pre,
+		"lines": func(s []string) string { return strings.Join(s, "\n") },
+	}).Parse(text)
+}
+
+func execute(tmpl *template.Template, c Card) (string, error) {
+	var b strings.Builder
+	b.Grow(600)
+	if err := tmpl.Execute(&b, c); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// mustTarget parses the template of a predefined target.
+func mustTarget(t Target) Target {
+	t, err := t.WithTemplate(t.Template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,260 @@
+package render
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/s4bb4t/lighthouse/pkg/core/levels"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func ExampleTarget_WithTemplate() {
+	short, _ := Slack.WithTemplate(`*{{esc .Message}}* {{code .Code}}`)
+
+	out, _ := short.Render(sperror.New(sperror.Sample{
+		Code:     "users.not_found",
+		Messages: map[string]string{sperror.En: "Not <found>"},
+	}), sperror.En)
+	fmt.Println(out)
+	// Output: *Not &lt;found&gt;* `users.not_found`
+}
+
+func TestTarget_Render(t *testing.T) {
+	e := sperror.New(sperror.Sample{
+		Code:     "users.not_found",
+		Messages: map[string]string{sperror.En: "Not found (v1.5)", sperror.Ru: "Не найдено"},
+		Desc:     "User <b> not_found",
+		Hint:     "Check `id`",
+		HttpCode: http.StatusNotFound,
+		Level:    levels.LevelUser,
+		Meta:     map[string]any{"b": "x|y", "a": 1},
+	})
+
+	tests := []struct {
+		name    string
+		target  Target
+		lang    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "markdown",
+			target: Markdown,
+			want: []string{
+				`### 🚨 Not found \(v1\.5\)`, "**Code:** `users.not_found`", `User \<b\> not\_found`,
+				"_Check \\`id\\`_", "- **a**: `1`\n- **b**: `x|y`",
+			},
+		},
+		{
+			name:    "text",
+			target:  Text,
+			lang:    sperror.Ru,
+			want:    []string{"Message: Не найдено", "Description: User <b> not_found", "Hint: Check `id`", "Meta:\n  a: 1\n  b: x|y"},
+			notWant: []string{"Stack:"},
+		},
+		{
+			name:   "telegram markdownv2",
+			target: TelegramMarkdownV2,
+			want: []string{
+				"\u2800\n", "🚨 *2*", "📝 `Not found (v1.5)`", `📖 User <b\> not\_found`, "💡 _Check \\`id\\`_",
+				"  • *a* → `1`\n  • *b* → `x|y`",
+			},
+		},
+		{
+			name:    "telegram html",
+			target:  TelegramHTML,
+			want:    []string{"<code>users.not_found</code>", "User &lt;b&gt; not_found", "<i>Check `id`</i>", "<b>Meta</b>"},
+			notWant: []string{"<b> not"},
+		},
+		{
+			name:    "slack",
+			target:  Slack,
+			want:    []string{"*Not found (v1.5)*", "*HTTP:* 404", "User &lt;b&gt; not_found", "• *b*: `x|y`"},
+			notWant: []string{"<b>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.target.Render(e, cmp.Or(tt.lang, sperror.En))
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("Render() lacks %q:\n%s", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("Render() contains %q:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTarget_Limit(t *testing.T) {
+	long := sperror.New(sperror.Sample{
+		Code:     "jobs.failed",
+		Messages: map[string]string{sperror.En: "Job failed"},
+		Desc:     strings.Repeat("very long description ", 300),
+		Level:    levels.LevelError,
+		Meta:     map[string]any{"payload": strings.Repeat("`x`", 2000)},
+	}).SetStack()
+	card := NewCard(long, sperror.En)
+	card.Stack = append(card.Stack, strings.Split(strings.Repeat("frame\n", 200), "\n")...)
+
+	for _, target := range []Target{Markdown, TelegramMarkdownV2, TelegramHTML, Slack} {
+		t.Run(target.Name, func(t *testing.T) {
+			target.Limit = 2000
+			got, err := target.RenderCard(card)
+			if err != nil {
+				t.Fatalf("RenderCard() error = %v", err)
+			}
+			if n := utf8.RuneCountInString(got); n > target.Limit {
+				t.Errorf("RenderCard() length = %d, limit %d", n, target.Limit)
+			}
+			if !strings.Contains(got, "Job failed") || !strings.Contains(got, "…") {
+				t.Errorf("RenderCard() lost the message or is not shortened:\n%s", got)
+			}
+			if strings.Count(got, "```")%2 != 0 || strings.Count(got, "<pre>") != strings.Count(got, "</pre>") {
+				t.Errorf("RenderCard() broke the markup:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestTarget_Code(t *testing.T) {
+	tests := []struct {
+		name   string
+		target Target
+		in     string
+		want   string
+	}{
+		{name: "markdown", target: Markdown, in: "a`b", want: "``a`b``"},
+		{name: "markdown edge", target: Markdown, in: "`a`", want: "`` `a` ``"},
+		{name: "markdownv2", target: TelegramMarkdownV2, in: "a`b\\c", want: "`a\\`b\\\\c`"},
+		{name: "html", target: TelegramHTML, in: "a<b>&", want: "<code>a&lt;b&gt;&amp;</code>"},
+		{name: "slack", target: Slack, in: "a`b<c", want: "`aˋb&lt;c`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.Code(tt.in); got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTarget_WithTemplate(t *testing.T) {
+	if _, err := Markdown.WithTemplate("{{.Unknown"); err == nil {
+		t.Errorf("WithTemplate() of a broken template error = nil")
+	}
+	custom, err := Text.WithTemplate("{{.Missing}}")
+	if err != nil {
+		t.Fatalf("WithTemplate() error = %v", err)
+	}
+	if _, err = custom.Render(sperror.New(sperror.Sample{}), sperror.En); err == nil {
+		t.Errorf("Render() with an unknown field error = nil")
+	}
+	if Text.Template == custom.Template {
+		t.Errorf("WithTemplate() modified the original target")
+	}
+}
+
+func TestTarget_LimitMarkup(t *testing.T) {
+	var g sperror.Group
+	for i := range 50 {
+		g.Add(fmt.Sprintf("field_%d", i), sperror.BadRequest("<b>&amp; `x` *y*", "Fix it"))
+	}
+	card := NewCard(sperror.Wrap(g.Err(), sperror.New(sperror.Sample{
+		Code:     "form.invalid",
+		Messages: map[string]string{sperror.En: "Form <is> *invalid*"},
+		Desc:     strings.Repeat("a_b&c ", 100),
+		Level:    levels.LevelUser,
+	})), sperror.En)
+
+	for _, target := range []Target{TelegramMarkdownV2, TelegramHTML} {
+		for _, limit := range []int{600, 100} {
+			t.Run(fmt.Sprintf("%s %d", target.Name, limit), func(t *testing.T) {
+				target.Limit = limit
+				got, err := target.RenderCard(card)
+				if err != nil {
+					t.Fatalf("RenderCard() error = %v", err)
+				}
+				if n := utf8.RuneCountInString(got); n > limit {
+					t.Errorf("RenderCard() length = %d, limit %d", n, limit)
+				}
+				if target.Name == TelegramHTML.Name {
+					for _, tag := range []string{"b", "i", "code"} {
+						if strings.Count(got, "<"+tag+">") != strings.Count(got, "</"+tag+">") {
+							t.Errorf("RenderCard() broke <%s>:\n%s", tag, got)
+						}
+					}
+				} else if strings.Count(got, "`")%2 != strings.Count(got, "\\`")%2 || strings.HasSuffix(strings.TrimSuffix(got, "…"), "\\") {
+					t.Errorf("RenderCard() broke the code entities:\n%s", got)
+				}
+			})
+		}
+	}
+}
+
+func TestLayerCard(t *testing.T) {
+	ctx := sperror.WithRequestID(context.Background(), "req-7")
+	inner := sperror.New(sperror.Sample{
+		Code:  "users.db",
+		Level: levels.LevelDebug,
+		Cause: fmt.Errorf("connection reset"),
+		Meta:  map[string]any{"request": map[string]any{"method": "GET", "path": "/users/42"}},
+	})
+	outer := sperror.WrapNew(inner, sperror.Sample{Code: "users.not_found", Level: levels.LevelUser}).WithContext(ctx)
+	group := new(sperror.Group).Add("email", sperror.BadRequest("invalid email", "")).Err()
+
+	tests := []struct {
+		name        string
+		layer       *sperror.Error
+		wantRequest []Entry
+		wantMeta    []Entry
+		wantCause   string
+		wantFields  int
+	}{
+		{
+			name:        "outer layer",
+			layer:       outer,
+			wantRequest: []Entry{{Key: "request_id", Value: "req-7"}},
+		},
+		{
+			name:      "inner layer",
+			layer:     inner,
+			wantMeta:  []Entry{{Key: "request.method", Value: "GET"}, {Key: "request.path", Value: "/users/42"}},
+			wantCause: "connection reset",
+		},
+		{
+			name:       "field errors",
+			layer:      group,
+			wantFields: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := LayerCard(tt.layer, sperror.En)
+			if c.Code != tt.layer.ErrCode() {
+				t.Errorf("LayerCard() code = %q, want %q", c.Code, tt.layer.ErrCode())
+			}
+			if !slices.Equal(c.Request, tt.wantRequest) || !slices.Equal(c.Meta, tt.wantMeta) {
+				t.Errorf("LayerCard() request = %v, meta = %v, want %v, %v", c.Request, c.Meta, tt.wantRequest, tt.wantMeta)
+			}
+			if c.Cause != tt.wantCause || len(c.Fields) != tt.wantFields {
+				t.Errorf("LayerCard() cause = %q, fields = %v, want %q, %d", c.Cause, c.Fields, tt.wantCause, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package render
+
+import (
+	"html"
+	"strings"
+)
+
+// Markdown renders GitHub-flavored Markdown for issues and postmortems.
+var Markdown = mustTarget(Target{
+	Name:   "markdown",
+	Limit:  65536, // GitHub comment limit
+	Escape: escapeMarkdown,
+	Code: func(s string) string {
+		fence := strings.Repeat("`", longestRun(s, '`')+1)
+		if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+			s = " " + s + " "
+		}
+		return fence + s + fence
+	},
+	Pre: func(s string) string {
+		fence := strings.Repeat("`", max(3, longestRun(s, '`')+1))
+		return fence + "\n" + s + "\n" + fence
+	},
+	Template: `### 🚨 {{if .Message}}{{esc .Message}}{{else}}Error{{end}}
+
+**Level:** {{esc .Level}}{{with .HttpCode}} · **HTTP:** {{.}}{{end}}{{with .Code}} · **Code:** {{code .}}{{end}}
+{{with .Desc}}
+**Description:** {{esc .}}
+{{end}}{{with .Hint}}
+**Hint:** _{{esc .}}_
+{{end}}{{with .Source}}
+**Source:** {{code .}}
+{{end}}{{with .Cause}}
+**Cause:** {{code .}}
+{{end}}{{with .Fields}}
+**Fields:**
+{{range .}}
+- **{{esc .Name}}**: {{esc .Message}}{{with .Desc}} ({{esc .}}){{end}}{{end}}
+{{end}}{{with .Request}}
+**Request:**
+{{range .}}
+- **{{esc .Key}}**: {{code .Value}}{{end}}
+{{end}}{{with .Meta}}
+**Meta:**
+{{range .}}
+- **{{esc .Key}}**: {{code .Value}}{{end}}
+{{end}}{{with .Stack}}
+<details><summary>Stack</summary>
+
+{{pre (lines .)}}
+
+</details>
+{{end}}`,
+})
+
+// Text renders plain text for logs, emails and terminals.
+var Text = mustTarget(Target{
+	Name:   "text",
+	Escape: func(s string) string { return s },
+	Code:   func(s string) string { return s },
+	Pre:    func(s string) string { return "    " + strings.ReplaceAll(s, "\n", "\n    ") },
+	Template: `Level: {{.Level}}{{with .HttpCode}}
+HTTP: {{.}}{{end}}{{with .Code}}
+Code: {{.}}{{end}}
+Message: {{.Message}}{{with .Desc}}
+Description: {{.}}{{end}}{{with .Hint}}
+Hint: {{.}}{{end}}{{with .Source}}
+Source: {{.}}{{end}}{{with .Cause}}
+Cause: {{.}}{{end}}{{with .Fields}}
+Fields:{{range .}}
+  - {{.Name}}: {{.Message}}{{with .Desc}} ({{.}}){{end}}{{end}}{{end}}{{with .Request}}
+Request:{{range .}}
+  {{.Key}}: {{.Value}}{{end}}{{end}}{{with .Meta}}
+Meta:{{range .}}
+  {{.Key}}: {{.Value}}{{end}}{{end}}{{with .Stack}}
+Stack:
+{{pre (lines .)}}{{end}}
+`,
+})
+
+// TelegramMarkdownV2 renders Telegram messages with the MarkdownV2 parse mode.
+var TelegramMarkdownV2 = mustTarget(Target{
+	Name:   "telegram-markdownv2",
+	Limit:  4096, // Telegram message limit
+	Escape: escapeMarkdownV2,
+	Code:   func(s string) string { return "`" + escapeMarkdownV2Code(s) + "`" },
+	Pre:    func(s string) string { return "```\n" + escapeMarkdownV2Code(s) + "\n```" },
+	Template: "\u2800\n\n\n" + `┌─ *Level*
+🚨 *{{esc .Level}}*
+
+{{with .Code}}┌─ *Code*
+🏷 {{code .}}
+
+{{end}}┌─ *Message*
+📝 {{code .Message}}
+
+{{with .Cause}}┌─ *Cause*
+💥 {{code .}}
+
+{{end}}{{with .Fields}}┌─ *Fields*
+{{range .}}  • *{{esc .Name}}* → {{code .Message}}{{with .Desc}} {{esc .}}{{end}}
+{{end}}
+{{end}}┌─ *Description*
+📖 {{esc .Desc}}
+
+┌─ *Hint*
+💡 _{{esc .Hint}}_
+
+┌─ *Source*
+🧭 || {{code .Source}} ||
+
+{{with .Stack}}┌─ *Stack*
+{{pre (lines .)}}
+
+{{end}}{{with .Request}}┌─ *Request*
+{{range .}}  • *{{esc .Key}}* → {{code .Value}}
+{{end}}
+{{end}}{{with .Meta}}┌─ *Meta*
+{{range .}}  • *{{esc .Key}}* → {{code .Value}}
+{{end}}
+{{end}}` + "\n\n\n\u2800",
+})
+
+// TelegramHTML renders Telegram messages with the HTML parse mode.
+var TelegramHTML = mustTarget(Target{
+	Name:   "telegram-html",
+	Limit:  4096,
+	Escape: html.EscapeString,
+	Code:   func(s string) string { return "<code>" + html.EscapeString(s) + "</code>" },
+	Pre:    func(s string) string { return "<pre>" + html.EscapeString(s) + "</pre>" },
+	Template: `🚨 <b>Level {{esc .Level}}</b>{{with .HttpCode}} · <b>{{.}}</b>{{end}}{{with .Code}} · {{code .}}{{end}}
+
+📝 <b>{{esc .Message}}</b>
+{{with .Desc}}📖 {{esc .}}
+{{end}}{{with .Hint}}💡 <i>{{esc .}}</i>
+{{end}}{{with .Cause}}
+💥 <b>Cause</b>
+{{code .}}
+{{end}}{{with .Fields}}
+<b>Fields</b>
+{{range .}}  • <b>{{esc .Name}}</b> → {{code .Message}}{{with .Desc}} {{esc .}}{{end}}
+{{end}}{{end}}{{with .Source}}
+🧭 <tg-spoiler>{{code .}}</tg-spoiler>
+{{end}}{{with .Stack}}
+<b>Stack</b>
+{{pre (lines .)}}
+{{end}}{{with .Request}}
+<b>Request</b>
+{{range .}}  • <b>{{esc .Key}}</b> → {{code .Value}}
+{{end}}{{end}}{{with .Meta}}
+<b>Meta</b>
+{{range .}}  • <b>{{esc .Key}}</b> → {{code .Value}}
+{{end}}{{end}}`,
+})
+
+// Slack renders Slack mrkdwn for the text of messages and section blocks.
+var Slack = mustTarget(Target{
+	Name:   "slack",
+	Limit:  3000, // section block text limit
+	Escape: escapeSlack,
+	// mrkdwn has no escapes for backticks, so they are replaced with a look-alike
+	Code: func(s string) string { return "`" + strings.ReplaceAll(escapeSlack(s), "`", "ˋ") + "`" },
+	Pre:  func(s string) string { return "```\n" + strings.ReplaceAll(escapeSlack(s), "`", "ˋ") + "\n```" },
+	Template: `:rotating_light: *{{if .Message}}{{esc .Message}}{{else}}Error{{end}}*
+*Level:* {{esc .Level}}{{with .HttpCode}}  *HTTP:* {{.}}{{end}}{{with .Code}}  *Code:* {{code .}}{{end}}
+{{with .Desc}}*Description:* {{esc .}}
+{{end}}{{with .Hint}}*Hint:* _{{esc .}}_
+{{end}}{{with .Source}}*Source:* {{code .}}
+{{end}}{{with .Cause}}*Cause:* {{code .}}
+{{end}}{{with .Fields}}*Fields:*
+{{range .}}• *{{esc .Name}}*: {{esc .Message}}{{with .Desc}} ({{esc .}}){{end}}
+{{end}}{{end}}{{with .Request}}*Request:*
+{{range .}}• *{{esc .Key}}*: {{code .Value}}
+{{end}}{{end}}{{with .Meta}}*Meta:*
+{{range .}}• *{{esc .Key}}*: {{code .Value}}
+{{end}}{{end}}{{with .Stack}}*Stack:*
+{{pre (lines .)}}
+{{end}}`,
+})
+
+var (
+	markdownEscaper = strings.NewReplacer(
+		"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "{", "\\{", "}", "\\}", "[", "\\[", "]", "\\]",
+		"<", "\\<", ">", "\\>", "(", "\\(", ")", "\\)", "#", "\\#", "+", "\\+", "-", "\\-", ".", "\\.",
+		"!", "\\!", "|", "\\|", "~", "\\~",
+	)
+	markdownV2Escaper = strings.NewReplacer(
+		"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "~", "\\~",
+		"`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=", "|", "\\|", "{", "\\{",
+		"}", "\\}", ".", "\\.", "!", "\\!",
+	)
+	markdownV2CodeEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+	slackEscaper          = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// escapeMarkdownV2 escapes every character reserved by Telegram MarkdownV2.
+func escapeMarkdownV2(s string) string {
+	return markdownV2Escaper.Replace(s)
+}
+
+// escapeMarkdownV2Code escapes text placed inside a MarkdownV2 code entity.
+func escapeMarkdownV2Code(s string) string {
+	return markdownV2CodeEscaper.Replace(s)
+}
+
+// escapeSlack escapes the control characters of mrkdwn; formatting characters cannot be escaped.
+func escapeSlack(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
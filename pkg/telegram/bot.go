@@ -3,6 +3,8 @@ package telegram
 import (
 	"github.com/s4bb4t/lighthouse/internal/storage"
 	"github.com/s4bb4t/lighthouse/pkg/core"
+	"github.com/s4bb4t/lighthouse/pkg/core/sperror"
+	"github.com/s4bb4t/lighthouse/pkg/render"
 	"sync"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -19,6 +21,8 @@ type Bot struct {
 	wh      func(b *Bot, addr, port string) (error, chan error)
 	storage core.Storage
 	Api     *tgbotapi.BotAPI
+	policy  *sperror.Policy
+	target  render.Target
 	sync.RWMutex
 }
 
@@ -49,5 +53,31 @@ func New(token string, groups []string) (*Bot, error) {
 		kb:      &k,
 		storage: repo,
 		Api:     api,
+		policy:  sperror.DefaultPolicy(),
+		target:  render.TelegramMarkdownV2,
 	}, nil
 }
+
+// SetRedaction sets the policy applied to errors before they are sent.
+// New bots use sperror.DefaultPolicy, nil disables redaction.
+func (b *Bot) SetRedaction(p *sperror.Policy) *Bot {
+	b.Lock()
+	defer b.Unlock()
+	b.policy = p
+	return b
+}
+
+// parseModes are the Telegram parse modes of render targets, other targets are sent as plain text.
+var parseModes = map[string]string{
+	render.TelegramMarkdownV2.Name: tgbotapi.ModeMarkdownV2,
+	render.TelegramHTML.Name:       tgbotapi.ModeHTML,
+}
+
+// SetRenderer sets the target errors are rendered with, e.g. render.TelegramHTML or
+// a target with a custom template. New bots use render.TelegramMarkdownV2.
+func (b *Bot) SetRenderer(t render.Target) *Bot {
+	b.Lock()
+	defer b.Unlock()
+	b.target = t
+	return b
+}
@@ -60,11 +60,22 @@ func (b *Bot) Error(e error, groups ...string) error {
 	b.RLock()
 	defer b.RUnlock()
 
-	msg := tgbotapi.NewMessage(0, prettify(e))
-	msg.ParseMode = "MarkdownV2"
+	text, err := b.target.Render(sperror.Ensure(e).Redact(b.policy), sperror.En)
+	if err != nil {
+		return sperror.New(sperror.Sample{
+			Messages: map[string]string{
+				sperror.En: "Failed to render message",
+			},
+			Desc:  "Template of the " + b.target.Name + " target failed",
+			Hint:  "Check the template set with SetRenderer",
+			Level: levels.LevelError,
+			Cause: err,
+		})
+	}
+	msg := tgbotapi.NewMessage(0, text)
+	msg.ParseMode = parseModes[b.target.Name]
 
 	var subs []int64
-	var err error
 
 	if groups == nil || len(groups) == 0 {
 		subs, err = b.readIds("")